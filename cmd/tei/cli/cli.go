@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/internal/errors"
 )
 
+// NewLineString is the newline that is appended to the standby string(e.g. string, template, env).
+var NewLineString = fmt.Sprintln()
+
 // Cli provides controler of command line tools.
 //
 // Cli はコマンドラインツールの制御を提供する.
@@ -31,7 +37,21 @@ type Builder interface {
 
 	FileCliBuilder
 	RunCliBuilder
+	IfDataCliBuilder
+	TryCliBuilder
+	EnvCliBuilder
+	FdCliBuilder
+	SocketCliBuilder
+	FifoCliBuilder
+	URLCliBuilder
+	TemplateCliBuilder
 	StringCliBuilder
+	RememberCliBuilder
+	LockCliBuilder
+	CheckCliBuilder
+	ChangedCliBuilder
+	NDJSONCliBuilder
+	LinesCliBuilder
 
 	Build() Cli
 }
@@ -47,9 +67,88 @@ type builder struct {
 	outStream  io.Writer
 	errStream  io.Writer
 
-	file       string
+	files      []string
+	missing    string
+	concat     bool
+	sel        string
+	maxAge     time.Duration
+	stale      string
 	cmdArgs    []string
+	chdir      string
+	env        []string
+	clearEnv   bool
+	envFile    string
+	exec       bool
+	cmdStdin   string
+	cache      bool
+	cacheTTL   time.Duration
+	cacheStale time.Duration
+	cacheEnv   []string
+	cacheDir   string
 	stringIntl string
+	// stringSet は String が呼び出されたか(空の文字列も standby として扱う).
+	stringSet bool
+
+	ifDataCmdArgs []string
+	noDataCmdArgs []string
+	tryCmdArgs    []string
+
+	envVar         string
+	envVarNewline  bool
+	envVarRequired bool
+
+	fd int
+
+	socket        string
+	socketRequest string
+	fifo          string
+	fifoTimeout   time.Duration
+
+	url         string
+	urlHeaders  []string
+	urlTimeout  time.Duration
+	urlCacheDir string
+
+	template     string
+	templateFile string
+	// templateSet は Template が呼び出されたか(空のテンプレートも standby として扱う).
+	templateSet bool
+
+	remember        string
+	rememberDir     string
+	rememberMaxSize int64
+	rememberTTL     time.Duration
+
+	lock        string
+	lockMode    string
+	lockTimeout time.Duration
+
+	check            bool
+	checkMinBytes    int
+	checkMinLines    int
+	checkMatch       []string
+	checkNoMatch     []string
+	checkJSONValid   bool
+	checkUTF8Valid   bool
+	checkPassThrough bool
+	checkReport      string
+	checkReportFile  string
+
+	changed            bool
+	changedKey         string
+	changedDir         string
+	changedUpdate      bool
+	changedPassThrough bool
+
+	ndjson         bool
+	ndjsonDefault  string
+	ndjsonTemplate string
+
+	lines                bool
+	linesDefault         string
+	linesDefaultEnv      string
+	linesDefaultTemplate string
+	linesNull            bool
 }
 
 func (b *builder) CmdName(cmdName string) Builder {
@@ -84,7 +183,47 @@ func (b *builder) ErrStream(errStream io.Writer) Builder {
 
 func (b *builder) File(file string) Builder {
 	bb := b.branch()
-	bb.file = file
+	if file == "" {
+		bb.files = nil
+		return bb
+	}
+	bb.files = []string{file}
+	return bb
+}
+
+func (b *builder) Files(files []string) Builder {
+	bb := b.branch()
+	bb.files = files
+	return bb
+}
+
+func (b *builder) Missing(missing string) Builder {
+	bb := b.branch()
+	bb.missing = missing
+	return bb
+}
+
+func (b *builder) Concat(concat bool) Builder {
+	bb := b.branch()
+	bb.concat = concat
+	return bb
+}
+
+func (b *builder) Select(sel string) Builder {
+	bb := b.branch()
+	bb.sel = sel
+	return bb
+}
+
+func (b *builder) MaxAge(maxAge time.Duration) Builder {
+	bb := b.branch()
+	bb.maxAge = maxAge
+	return bb
+}
+
+func (b *builder) Stale(stale string) Builder {
+	bb := b.branch()
+	bb.stale = stale
 	return bb
 }
 
@@ -94,9 +233,359 @@ func (b *builder) CmdArgs(cmdArgs []string) Builder {
 	return bb
 }
 
+func (b *builder) Chdir(chdir string) Builder {
+	bb := b.branch()
+	bb.chdir = chdir
+	return bb
+}
+
+func (b *builder) Env(env []string) Builder {
+	bb := b.branch()
+	bb.env = env
+	return bb
+}
+
+func (b *builder) ClearEnv(clearEnv bool) Builder {
+	bb := b.branch()
+	bb.clearEnv = clearEnv
+	return bb
+}
+
+func (b *builder) EnvFile(envFile string) Builder {
+	bb := b.branch()
+	bb.envFile = envFile
+	return bb
+}
+
+func (b *builder) IfDataCmdArgs(ifDataCmdArgs []string) Builder {
+	bb := b.branch()
+	bb.ifDataCmdArgs = ifDataCmdArgs
+	return bb
+}
+
+func (b *builder) NoDataCmdArgs(noDataCmdArgs []string) Builder {
+	bb := b.branch()
+	bb.noDataCmdArgs = noDataCmdArgs
+	return bb
+}
+
+func (b *builder) TryCmdArgs(tryCmdArgs []string) Builder {
+	bb := b.branch()
+	bb.tryCmdArgs = tryCmdArgs
+	return bb
+}
+
+func (b *builder) Exec(exec bool) Builder {
+	bb := b.branch()
+	bb.exec = exec
+	return bb
+}
+
+func (b *builder) CmdStdin(cmdStdin string) Builder {
+	bb := b.branch()
+	bb.cmdStdin = cmdStdin
+	return bb
+}
+
+func (b *builder) Cache(cache bool) Builder {
+	bb := b.branch()
+	bb.cache = cache
+	return bb
+}
+
+func (b *builder) CacheTTL(cacheTTL time.Duration) Builder {
+	bb := b.branch()
+	bb.cacheTTL = cacheTTL
+	return bb
+}
+
+func (b *builder) CacheStale(cacheStale time.Duration) Builder {
+	bb := b.branch()
+	bb.cacheStale = cacheStale
+	return bb
+}
+
+func (b *builder) CacheEnv(cacheEnv []string) Builder {
+	bb := b.branch()
+	bb.cacheEnv = cacheEnv
+	return bb
+}
+
+func (b *builder) CacheDir(cacheDir string) Builder {
+	bb := b.branch()
+	bb.cacheDir = cacheDir
+	return bb
+}
+
+func (b *builder) EnvVar(envVar string) Builder {
+	bb := b.branch()
+	bb.envVar = envVar
+	return bb
+}
+
+func (b *builder) EnvVarNewline(envVarNewline bool) Builder {
+	bb := b.branch()
+	bb.envVarNewline = envVarNewline
+	return bb
+}
+
+func (b *builder) EnvVarRequired(envVarRequired bool) Builder {
+	bb := b.branch()
+	bb.envVarRequired = envVarRequired
+	return bb
+}
+
+func (b *builder) Fd(fd int) Builder {
+	bb := b.branch()
+	bb.fd = fd
+	return bb
+}
+
+func (b *builder) Socket(socket string) Builder {
+	bb := b.branch()
+	bb.socket = socket
+	return bb
+}
+
+func (b *builder) SocketRequest(socketRequest string) Builder {
+	bb := b.branch()
+	bb.socketRequest = socketRequest
+	return bb
+}
+
+func (b *builder) Fifo(fifo string) Builder {
+	bb := b.branch()
+	bb.fifo = fifo
+	return bb
+}
+
+func (b *builder) FifoTimeout(fifoTimeout time.Duration) Builder {
+	bb := b.branch()
+	bb.fifoTimeout = fifoTimeout
+	return bb
+}
+
+func (b *builder) URL(url string) Builder {
+	bb := b.branch()
+	bb.url = url
+	return bb
+}
+
+func (b *builder) URLHeaders(urlHeaders []string) Builder {
+	bb := b.branch()
+	bb.urlHeaders = urlHeaders
+	return bb
+}
+
+func (b *builder) URLTimeout(urlTimeout time.Duration) Builder {
+	bb := b.branch()
+	bb.urlTimeout = urlTimeout
+	return bb
+}
+
+func (b *builder) URLCacheDir(urlCacheDir string) Builder {
+	bb := b.branch()
+	bb.urlCacheDir = urlCacheDir
+	return bb
+}
+
+func (b *builder) Template(template string) Builder {
+	bb := b.branch()
+	bb.template = template
+	bb.templateSet = true
+	return bb
+}
+
+func (b *builder) TemplateFile(templateFile string) Builder {
+	bb := b.branch()
+	bb.templateFile = templateFile
+	return bb
+}
+
 func (b *builder) String(stringIntl string) Builder {
 	bb := b.branch()
 	bb.stringIntl = stringIntl
+	bb.stringSet = true
+	return bb
+}
+
+func (b *builder) Remember(remember string) Builder {
+	bb := b.branch()
+	bb.remember = remember
+	return bb
+}
+
+func (b *builder) RememberDir(rememberDir string) Builder {
+	bb := b.branch()
+	bb.rememberDir = rememberDir
+	return bb
+}
+
+func (b *builder) RememberMaxSize(rememberMaxSize int64) Builder {
+	bb := b.branch()
+	bb.rememberMaxSize = rememberMaxSize
+	return bb
+}
+
+func (b *builder) RememberTTL(rememberTTL time.Duration) Builder {
+	bb := b.branch()
+	bb.rememberTTL = rememberTTL
+	return bb
+}
+
+func (b *builder) Lock(lock string) Builder {
+	bb := b.branch()
+	bb.lock = lock
+	return bb
+}
+
+func (b *builder) LockMode(lockMode string) Builder {
+	bb := b.branch()
+	bb.lockMode = lockMode
+	return bb
+}
+
+func (b *builder) LockTimeout(lockTimeout time.Duration) Builder {
+	bb := b.branch()
+	bb.lockTimeout = lockTimeout
+	return bb
+}
+
+func (b *builder) Check(check bool) Builder {
+	bb := b.branch()
+	bb.check = check
+	return bb
+}
+
+func (b *builder) CheckMinBytes(checkMinBytes int) Builder {
+	bb := b.branch()
+	bb.checkMinBytes = checkMinBytes
+	return bb
+}
+
+func (b *builder) CheckMinLines(checkMinLines int) Builder {
+	bb := b.branch()
+	bb.checkMinLines = checkMinLines
+	return bb
+}
+
+func (b *builder) CheckMatch(checkMatch []string) Builder {
+	bb := b.branch()
+	bb.checkMatch = checkMatch
+	return bb
+}
+
+func (b *builder) CheckNoMatch(checkNoMatch []string) Builder {
+	bb := b.branch()
+	bb.checkNoMatch = checkNoMatch
+	return bb
+}
+
+func (b *builder) CheckJSONValid(checkJSONValid bool) Builder {
+	bb := b.branch()
+	bb.checkJSONValid = checkJSONValid
+	return bb
+}
+
+func (b *builder) CheckUTF8Valid(checkUTF8Valid bool) Builder {
+	bb := b.branch()
+	bb.checkUTF8Valid = checkUTF8Valid
+	return bb
+}
+
+func (b *builder) CheckPassThrough(checkPassThrough bool) Builder {
+	bb := b.branch()
+	bb.checkPassThrough = checkPassThrough
+	return bb
+}
+
+func (b *builder) CheckReport(checkReport string) Builder {
+	bb := b.branch()
+	bb.checkReport = checkReport
+	return bb
+}
+
+func (b *builder) CheckReportFile(checkReportFile string) Builder {
+	bb := b.branch()
+	bb.checkReportFile = checkReportFile
+	return bb
+}
+
+func (b *builder) Changed(changed bool) Builder {
+	bb := b.branch()
+	bb.changed = changed
+	return bb
+}
+
+func (b *builder) ChangedKey(changedKey string) Builder {
+	bb := b.branch()
+	bb.changedKey = changedKey
+	return bb
+}
+
+func (b *builder) ChangedDir(changedDir string) Builder {
+	bb := b.branch()
+	bb.changedDir = changedDir
+	return bb
+}
+
+func (b *builder) ChangedUpdate(changedUpdate bool) Builder {
+	bb := b.branch()
+	bb.changedUpdate = changedUpdate
+	return bb
+}
+
+func (b *builder) ChangedPassThrough(changedPassThrough bool) Builder {
+	bb := b.branch()
+	bb.changedPassThrough = changedPassThrough
+	return bb
+}
+
+func (b *builder) NDJSON(ndjson bool) Builder {
+	bb := b.branch()
+	bb.ndjson = ndjson
+	return bb
+}
+
+func (b *builder) NDJSONDefault(ndjsonDefault string) Builder {
+	bb := b.branch()
+	bb.ndjsonDefault = ndjsonDefault
+	return bb
+}
+
+func (b *builder) NDJSONTemplate(ndjsonTemplate string) Builder {
+	bb := b.branch()
+	bb.ndjsonTemplate = ndjsonTemplate
+	return bb
+}
+
+func (b *builder) Lines(lines bool) Builder {
+	bb := b.branch()
+	bb.lines = lines
+	return bb
+}
+
+func (b *builder) LinesDefault(linesDefault string) Builder {
+	bb := b.branch()
+	bb.linesDefault = linesDefault
+	return bb
+}
+
+func (b *builder) LinesDefaultEnv(linesDefaultEnv string) Builder {
+	bb := b.branch()
+	bb.linesDefaultEnv = linesDefaultEnv
+	return bb
+}
+
+func (b *builder) LinesDefaultTemplate(linesDefaultTemplate string) Builder {
+	bb := b.branch()
+	bb.linesDefaultTemplate = linesDefaultTemplate
+	return bb
+}
+
+func (b *builder) LinesNull(linesNull bool) Builder {
+	bb := b.branch()
+	bb.linesNull = linesNull
 	return bb
 }
 
@@ -110,12 +599,36 @@ func (b *builder) Branch() Builder {
 
 func (b *builder) Build() Cli {
 	switch {
-	case b.file != "":
+	case b.check:
+		return newCheckCli(b)
+	case b.changed:
+		return newChangedCli(b)
+	case b.ndjson:
+		return newNDJSONCli(b)
+	case b.lines:
+		return newLinesCli(b)
+	case len(b.tryCmdArgs) > 0:
+		return newTryCli(b)
+	case len(b.ifDataCmdArgs) > 0 || len(b.noDataCmdArgs) > 0:
+		return newIfDataCli(b)
+	case len(b.files) > 0:
 		return newFileCli(b)
 	case len(b.cmdArgs) > 0:
 		return newRunCli(b)
-	case b.stringIntl != "":
+	case b.stringSet:
 		return newStringCli(b)
+	case b.envVar != "":
+		return newEnvCli(b)
+	case b.fd >= 0:
+		return newFdCli(b)
+	case b.socket != "":
+		return newSocketCli(b)
+	case b.fifo != "":
+		return newFifoCli(b)
+	case b.url != "":
+		return newURLCli(b)
+	case b.templateSet || b.templateFile != "":
+		return newTemplateCli(b)
 	}
 	return newBaseCli(b)
 }
@@ -127,6 +640,15 @@ type baseCli struct {
 	inStream   io.Reader
 	outStream  io.Writer
 	errStream  io.Writer
+
+	remember        string
+	rememberDir     string
+	rememberMaxSize int64
+	rememberTTL     time.Duration
+
+	lock        string
+	lockMode    string
+	lockTimeout time.Duration
 }
 
 func (c *baseCli) CmdName() string {
@@ -149,16 +671,122 @@ func (c *baseCli) ErrStream() io.Writer {
 	return c.errStream
 }
 
+// switchInput switches the input by Tei that is built from teiBuilder.
+//
+// remember と lock が設定されていない場合は Tei.Switch と同じ.
+// remember が設定されている場合、入力にデータがあれば保存し、
+// データがなければ standby の代わりに保存された入力を返す.
+// lock が設定されている場合、standby へ切り替える前にロックを取得する.
+// 戻り値の done は読み込みが終わった後に呼び出す(ロックはここで解放される).
+func (c *baseCli) switchInput(ctx context.Context, teiBuilder tei.Builder, input io.Reader) (r io.Reader, done func(), err error) {
+	done = func() {}
+	if c.remember == "" && c.lock == "" {
+		return teiBuilder.Build().Switch(input), done, nil
+	}
+	var store *rememberStore
+	if c.remember != "" {
+		store, err = newRememberStore(c.rememberDir, c.remember, c.rememberMaxSize, c.rememberTTL)
+		if err != nil {
+			return nil, done, err
+		}
+	}
+
+	// standby を実行する前に入力の有無を確認する.
+	t := teiBuilder.Build()
+	var reason tei.Reason
+	var sniffed []byte
+	r = teiBuilder.OnSwitch(func(rs tei.Reason, b []byte) {
+		reason = rs
+		sniffed = b
+	}).Standby(func() io.Reader {
+		return nil
+	}).Build().Switch(input)
+
+	if r != nil {
+		if store == nil {
+			return r, done, nil
+		}
+		w, commit, abort, err := store.writer()
+		if err != nil {
+			fmt.Fprintf(c.errStream, "Warning in Cli: the input is not remembered: %s\n", err)
+			return r, done, nil
+		}
+		cr := &cachingReader{r: r, w: w, commit: commit, abort: abort, warn: func(err error) {
+			fmt.Fprintf(c.errStream, "Warning in Cli: the input is not remembered: %s\n", err)
+		}}
+		return cr, cr.close, nil
+	}
+
+	if store != nil {
+		file, err := store.load()
+		if err != nil {
+			return nil, done, err
+		}
+		if file != nil {
+			return file, func() { file.Close() }, nil
+		}
+	}
+	if c.lock != "" {
+		file, err := acquireLock(ctx, c.lock, c.lockMode, c.lockTimeout)
+		if err != nil {
+			return nil, done, err
+		}
+		done = func() { file.Close() }
+	}
+	// sniff した内容を再現して standby へ切り替える(端末の場合は読み込んでいない).
+	if reason == tei.ReasonTerminal {
+		return t.Switch(input), done, nil
+	}
+	return t.Switch(bytes.NewReader(sniffed)), done, nil
+}
+
+// switchedInput returns the whole input that is switched, it is the sniffed bytes and the rest of the input.
+//
+// JSONPath の場合は対象のノードを判定した時点で sniff を終えるので、sniffed は入力の先頭部分のみとなる.
+// 端末の場合は入力を読み込まない.
+func switchedInput(reason tei.Reason, sniffed []byte, input io.Reader) io.Reader {
+	if reason == tei.ReasonTerminal || input == nil {
+		return bytes.NewReader(sniffed)
+	}
+	return io.MultiReader(bytes.NewReader(sniffed), input)
+}
+
+// errExitCode returns the exit code of Cli.Run by err.
+func errExitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.exitCode
+	}
+	return 1
+}
+
 func (c *baseCli) Run(ctx context.Context) (exitCode int, err error) {
-	tei := c.teiBuilder.Build()
-	_, err = io.Copy(c.outStream, tei.Switch(c.inStream))
+	r, done, err := c.switchInput(ctx, c.teiBuilder, c.inStream)
+	defer done()
+	if err != nil {
+		return errExitCode(err), errors.Wrapf(err, "Cli.Run switching the input")
+	}
+	_, err = io.Copy(c.outStream, r)
 	if err != nil {
-		// TODO: err により exit code を変更.
-		return 1, errors.Wrapf(err, "Cli.Run reading the switched input")
+		return errExitCode(err), errors.Wrapf(err, "Cli.Run reading the switched input")
 	}
 	return
 }
 
+// exitCodeError is the error that has the exit code of Cli.Run.
+type exitCodeError struct {
+	exitCode int
+	err      error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
 type errCli struct {
 	baseCli
 	exitCode int
@@ -176,6 +804,15 @@ func newBaseCli(b *builder) *baseCli {
 		inStream:   b.inStream,
 		outStream:  b.outStream,
 		errStream:  b.errStream,
+
+		remember:        b.remember,
+		rememberDir:     b.rememberDir,
+		rememberMaxSize: b.rememberMaxSize,
+		rememberTTL:     b.rememberTTL,
+
+		lock:        b.lock,
+		lockMode:    b.lockMode,
+		lockTimeout: b.lockTimeout,
 	}
 }
 
@@ -198,5 +835,6 @@ func NewBuilder() Builder {
 		inStream:   os.Stdin,
 		outStream:  os.Stdout,
 		errStream:  os.Stderr,
+		fd:         -1,
 	}
 }
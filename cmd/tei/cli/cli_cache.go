@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// cacheDir returns dir if it is set, otherwise returns "$XDG_CACHE_HOME/tei/<name>".
+func cacheDir(dir string, name string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userCacheDir, "tei", name), nil
+}
+
+// cacheTempFile creates the temporary file in the cache dir.
+func cacheTempFile(dir string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "cacheTempFile make cache dir")
+	}
+	tmp, err := ioutil.TempFile(dir, ".tmp-")
+	if err != nil {
+		return nil, errors.Wrapf(err, "cacheTempFile create temp file")
+	}
+	return tmp, nil
+}
+
+// cacheWriter returns the temporary file in the cache dir and the function to commit it as name.
+//
+// commit されるまでは既存のキャッシュは置き換えない(rename で置き換える).
+func cacheWriter(dir string, name string) (tmp *os.File, commit func() error, abort func(), err error) {
+	tmp, err = cacheTempFile(dir)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	abort = func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	commit = func() error {
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return errors.Wrapf(err, "cacheWriter close temp file")
+		}
+		if err := os.Rename(tmp.Name(), name); err != nil {
+			os.Remove(tmp.Name())
+			return errors.Wrapf(err, "cacheWriter rename temp file")
+		}
+		return nil
+	}
+	return tmp, commit, abort, nil
+}
+
+// cachingReader copies the input to the cache while reading, commits it at io.EOF.
+//
+// キャッシュへの書き込みの失敗は入力の読み込みには影響しない(warn で警告のみ行う).
+type cachingReader struct {
+	r      io.Reader
+	w      io.Writer
+	commit func() error
+	abort  func()
+	warn   func(error)
+	done   bool
+}
+
+func (c *cachingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 && c.done == false {
+		if _, werr := c.w.Write(p[:n]); werr != nil {
+			c.abort()
+			c.done = true
+		}
+	}
+	if err != nil && c.done == false {
+		c.done = true
+		if err == io.EOF {
+			if cerr := c.commit(); cerr != nil {
+				c.warn(cerr)
+			}
+		} else {
+			c.abort()
+		}
+	}
+	return n, err
+}
+
+// close aborts the cache if the input is not read to the end.
+func (c *cachingReader) close() {
+	if c.done == false {
+		c.done = true
+		c.abort()
+	}
+}
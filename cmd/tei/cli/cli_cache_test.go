@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_cachingReader(t *testing.T) {
+	type args struct {
+		input     string
+		commitErr error
+		writeErr  error
+	}
+	tests := []struct {
+		name          string
+		args          args
+		want          string
+		wantCache     string
+		wantCommitted bool
+		wantAborted   bool
+		wantWarn      string
+	}{
+		{
+			name: "basic",
+			args: args{
+				input: "input data",
+			},
+			want:          "input data",
+			wantCache:     "input data",
+			wantCommitted: true,
+		}, {
+			name: "commit error",
+			args: args{
+				input:     "input data",
+				commitErr: fmt.Errorf("rename failed"),
+			},
+			want:          "input data",
+			wantCache:     "input data",
+			wantCommitted: true,
+			wantWarn:      "rename failed",
+		}, {
+			name: "write error",
+			args: args{
+				input:    "input data",
+				writeErr: fmt.Errorf("disk full"),
+			},
+			want:        "input data",
+			wantAborted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &bytes.Buffer{}
+			committed := false
+			aborted := false
+			warn := ""
+			r := &cachingReader{
+				r: strings.NewReader(tt.args.input),
+				w: &errWriter{w: cache, err: tt.args.writeErr},
+				commit: func() error {
+					committed = true
+					return tt.args.commitErr
+				},
+				abort: func() {
+					aborted = true
+				},
+				warn: func(err error) {
+					warn = err.Error()
+				},
+			}
+			got, err := ioutil.ReadAll(r)
+			r.close()
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, string(got), "read")
+				assert.Equal(t, tt.wantCache, cache.String(), "cache")
+				assert.Equal(t, tt.wantCommitted, committed, "committed")
+				assert.Equal(t, tt.wantAborted, aborted, "aborted")
+				assert.Equal(t, tt.wantWarn, warn, "warn")
+			}
+		})
+	}
+}
+
+type errWriter struct {
+	w   *bytes.Buffer
+	err error
+}
+
+func (w *errWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.w.Write(p)
+}
+
+func Test_cacheWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "cache", "test")
+
+	tmp, commit, abort, err := cacheWriter(filepath.Dir(name), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.WriteString("aborted data")
+	abort()
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err), "abort")
+
+	tmp, commit, _, err = cacheWriter(filepath.Dir(name), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp.WriteString("committed data")
+	if assert.NoError(t, commit()) {
+		b, err := ioutil.ReadFile(name)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "committed data", string(b))
+		}
+	}
+	files, err := ioutil.ReadDir(filepath.Dir(name))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(files), "temp files are removed")
+	}
+
+	got, err := cacheDir(dir, "run")
+	if assert.NoError(t, err) {
+		assert.Equal(t, dir, got)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+const (
+	// ChangedExitCodeUnchanged is the exit code of Cli.Run if the input is not changed from the last time.
+	ChangedExitCodeUnchanged = 1
+	// ChangedExitCodeError is the exit code of Cli.Run if the input is not compared by the error.
+	ChangedExitCodeError = 2
+)
+
+// changedCli compares the digest of the input with the stored digest.
+//
+// 入力はバッファリングせずに digest を計算する(pass-through の場合はそのまま出力する).
+// 入力にデータがない場合(改行のみ等)は sniff された内容を入力として扱う.
+type changedCli struct {
+	baseCli
+	changedKey         string
+	changedDir         string
+	changedUpdate      bool
+	changedPassThrough bool
+}
+
+// ChangedCliBuilder adds a property to CliBuilder.
+type ChangedCliBuilder interface {
+	// Changed sets the flag that the input is compared with the last time instead of switching.
+	Changed(bool) Builder
+	// ChangedKey sets the key that the digest of the input is stored with.
+	ChangedKey(string) Builder
+	// ChangedDir sets the directory that the digest is stored in(default is "$XDG_CACHE_HOME/tei/changed").
+	ChangedDir(string) Builder
+	// ChangedUpdate sets the flag that the stored digest is updated.
+	ChangedUpdate(bool) Builder
+	// ChangedPassThrough sets the flag that the input is written to the output as is.
+	ChangedPassThrough(bool) Builder
+}
+
+// store returns the store of the digest.
+//
+// 保存先が異なるだけなので rememberStore を利用する(キーの検証と atomic な更新).
+func (c *changedCli) store() (*rememberStore, error) {
+	dir, err := cacheDir(c.changedDir, "changed")
+	if err != nil {
+		return nil, errors.Wrapf(err, "changedCli.Run cache dir")
+	}
+	return newRememberStore(dir, c.changedKey, 0, 0)
+}
+
+// lastDigest returns the stored digest, returns "" if not stored.
+func lastDigest(store *rememberStore) (string, error) {
+	file, err := store.load()
+	if err != nil || file == nil {
+		return "", err
+	}
+	defer file.Close()
+	b, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "changedCli.Run reading the digest")
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func (c *changedCli) Run(ctx context.Context) (exitCode int, err error) {
+	store, err := c.store()
+	if err != nil {
+		return ChangedExitCodeError, err
+	}
+
+	var reason tei.Reason
+	var sniffed []byte
+	r, done, err := c.switchInput(ctx, c.teiBuilder.OnSwitch(func(rs tei.Reason, b []byte) {
+		reason = rs
+		sniffed = b
+	}).Standby(func() io.Reader {
+		return nil
+	}), c.inStream)
+	defer done()
+	if err != nil {
+		return ChangedExitCodeError, errors.Wrapf(err, "changedCli.Run switching the input")
+	}
+	if r == nil {
+		// --json-path の場合は sniffed が入力の先頭部分のみとなるので、残りも続ける.
+		r = switchedInput(reason, sniffed, c.inStream)
+	}
+
+	h := sha256.New()
+	var w io.Writer = h
+	if c.changedPassThrough {
+		w = io.MultiWriter(h, c.outStream)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return ChangedExitCodeError, errors.Wrapf(err, "changedCli.Run reading the input")
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	last, err := lastDigest(store)
+	if err != nil {
+		return ChangedExitCodeError, err
+	}
+	if last == digest {
+		return ChangedExitCodeUnchanged, nil
+	}
+	if c.changedUpdate {
+		dw, commit, abort, err := store.writer()
+		if err != nil {
+			return ChangedExitCodeError, err
+		}
+		if _, err := io.WriteString(dw, digest+"\n"); err != nil {
+			abort()
+			return ChangedExitCodeError, errors.Wrapf(err, "changedCli.Run writing the digest")
+		}
+		if err := commit(); err != nil {
+			return ChangedExitCodeError, err
+		}
+	}
+	return 0, nil
+}
+
+func newChangedCli(b *builder) *changedCli {
+	return &changedCli{
+		baseCli:            *newBaseCli(b),
+		changedKey:         b.changedKey,
+		changedDir:         b.changedDir,
+		changedUpdate:      b.changedUpdate,
+		changedPassThrough: b.changedPassThrough,
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_changedCli_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 各ケースは順番に実行される(保存された digest は次のケースへ引き継がれる).
+	tests := []struct {
+		name         string
+		builder      Builder
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "first time",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir),
+			want: "",
+		}, {
+			name: "not updated",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir),
+			want: "",
+		}, {
+			name: "update",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir).
+				ChangedUpdate(true).
+				ChangedPassThrough(true),
+			want: "input data",
+		}, {
+			name: "unchanged",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir).
+				ChangedUpdate(true).
+				ChangedPassThrough(true),
+			want:         "input data",
+			wantExitCode: ChangedExitCodeUnchanged,
+		}, {
+			name: "changed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data\n")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir).
+				ChangedUpdate(true),
+			want: "",
+		}, {
+			name: "newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir).
+				ChangedUpdate(true).
+				ChangedPassThrough(true),
+			want: "\n",
+		}, {
+			name: "newline unchanged",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Changed(true).
+				ChangedKey("test").
+				ChangedDir(dir).
+				ChangedPassThrough(true),
+			want:         "\n",
+			wantExitCode: ChangedExitCodeUnchanged,
+		}, {
+			name: "other key",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Changed(true).
+				ChangedKey("test2").
+				ChangedDir(dir),
+			want: "",
+		}, {
+			name: "json path",
+			builder: NewBuilder().
+				TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+				InStream(strings.NewReader(`{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`)).
+				Changed(true).
+				ChangedKey("json").
+				ChangedDir(dir).
+				ChangedUpdate(true).
+				ChangedPassThrough(true),
+			want: `{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`,
+		}, {
+			name: "json path changed after the sniffed bytes",
+			builder: NewBuilder().
+				TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+				InStream(strings.NewReader(`{"items":[],"next":"` + strings.Repeat("x", 1999) + `y"}`)).
+				Changed(true).
+				ChangedKey("json").
+				ChangedDir(dir),
+			want: "",
+		}, {
+			name: "invalid key",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Changed(true).
+				ChangedKey("../test").
+				ChangedDir(dir),
+			want:         "",
+			wantExitCode: ChangedExitCodeError,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(errStream).
+				Build()
+			gotExitCode, err := c.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("changedCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("changedCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "changedCli.Run() outStream")
+			assert.Equal(t, "", errStream.String(), "changedCli.Run() errStream")
+		})
+	}
+
+	// 一時ファイルは残らない.
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "json"), filepath.Join(dir, "test")}, files, "changedCli.Run() stored files")
+}
@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+const (
+	// CheckExitCodeFailed is the exit code of Cli.Run if the input does not satisfy the predicates.
+	CheckExitCodeFailed = 1
+	// CheckExitCodeError is the exit code of Cli.Run if the input is not checked by the error.
+	CheckExitCodeError = 2
+)
+
+const (
+	// CheckReportJSON is that the report is written as JSON.
+	CheckReportJSON = "json"
+	// CheckReportEnv is that the report is written as "KEY=VALUE" lines that can be evaluated by the shell.
+	CheckReportEnv = "env"
+)
+
+// checkReportFirstLineMax is the max size of the first line in the report.
+const checkReportFirstLineMax = 80
+
+// checkReadSize is the size of the buffer to read the input.
+const checkReadSize = 32 * 1024
+
+// checkCli checks the input by the predicates.
+//
+// 入力にデータがない場合(tei の判定による)は常に失敗となる.
+// 各 predicate は全て満たす必要がある(AND).
+type checkCli struct {
+	baseCli
+	checkMinBytes    int
+	checkMinLines    int
+	checkMatch       []string
+	checkNoMatch     []string
+	checkJSONValid   bool
+	checkUTF8Valid   bool
+	checkPassThrough bool
+	checkReport      string
+	checkReportFile  string
+}
+
+// CheckCliBuilder adds a property to CliBuilder.
+type CheckCliBuilder interface {
+	// Check sets the flag that the input is checked instead of switching.
+	Check(bool) Builder
+	// CheckMinBytes sets the minimum size of the input.
+	CheckMinBytes(int) Builder
+	// CheckMinLines sets the minimum number of lines of the input.
+	CheckMinLines(int) Builder
+	// CheckMatch sets the regular expressions that must match the input.
+	CheckMatch([]string) Builder
+	// CheckNoMatch sets the regular expressions that must not match the input.
+	CheckNoMatch([]string) Builder
+	// CheckJSONValid sets the flag that the input must be valid JSON.
+	CheckJSONValid(bool) Builder
+	// CheckUTF8Valid sets the flag that the input must be valid UTF-8.
+	CheckUTF8Valid(bool) Builder
+	// CheckPassThrough sets the flag that the input is written to the output as is.
+	CheckPassThrough(bool) Builder
+	// CheckReport sets the format of the report(CheckReportJSON, CheckReportEnv).
+	CheckReport(string) Builder
+	// CheckReportFile sets the file that the report is written to(default is the error stream).
+	CheckReportFile(string) Builder
+}
+
+// checkReport is the classification of the input.
+//
+// SniffedBytes は切り替えの判定のために読み込んだサイズ、InputBytes は入力全体のサイズ.
+type checkReport struct {
+	Empty        bool   `json:"empty"`
+	Reason       string `json:"reason"`
+	SniffedBytes int    `json:"sniffed_bytes"`
+	InputBytes   int    `json:"input_bytes"`
+	FirstLine    string `json:"first_line"`
+	InputType    string `json:"input_type"`
+	PassThrough  bool   `json:"pass_through"`
+	Passed       bool   `json:"passed"`
+	ExitCode     int    `json:"exit_code"`
+}
+
+// firstLine returns the first line(without newline) that is truncated to max bytes.
+func firstLine(b []byte, max int) string {
+	if i := bytes.IndexAny(b, "\r\n"); i >= 0 {
+		b = b[:i]
+	}
+	if len(b) > max {
+		b = b[:max]
+		// 途中で切れた UTF-8 の文字は除く.
+		for len(b) > 0 && utf8.Valid(b) == false {
+			b = b[:len(b)-1]
+		}
+	}
+	return string(b)
+}
+
+// inputType returns the type of the input(tty, pipe, file, null, socket, other).
+func inputType(r io.Reader) string {
+	file, ok := r.(*os.File)
+	if ok == false {
+		return "other"
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return "other"
+	}
+	mode := stat.Mode()
+	switch {
+	case mode&os.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&os.ModeSocket != 0:
+		return "socket"
+	case mode&os.ModeDevice != 0:
+		if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(stat, null) {
+			return "null"
+		}
+		return "tty"
+	case mode.IsRegular():
+		return "file"
+	}
+	return "other"
+}
+
+// shellQuote quotes s by single quotes.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// write writes the report in the format.
+func (r *checkReport) write(w io.Writer, format string) error {
+	switch format {
+	case CheckReportJSON:
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	case CheckReportEnv:
+		_, err := fmt.Fprintf(w, "TEI_EMPTY=%t\nTEI_REASON=%s\nTEI_SNIFFED_BYTES=%d\nTEI_INPUT_BYTES=%d\nTEI_FIRST_LINE=%s\nTEI_INPUT_TYPE=%s\nTEI_PASS_THROUGH=%t\nTEI_PASSED=%t\nTEI_EXIT_CODE=%d\n",
+			r.Empty, shellQuote(r.Reason), r.SniffedBytes, r.InputBytes, shellQuote(r.FirstLine), shellQuote(r.InputType), r.PassThrough, r.Passed, r.ExitCode)
+		return err
+	}
+	return fmt.Errorf("invalid report format: %s", format)
+}
+
+// countingReader counts the bytes that are read.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// checkStat counts the input incrementally while it is written(the input is not buffered).
+type checkStat struct {
+	bytes       int
+	newLines    int
+	last        byte
+	head        []byte
+	headMax     int
+	invalidUTF8 bool
+	// pending は末尾で途中になっている UTF-8 の文字(次の Write で補う).
+	pending []byte
+}
+
+func newCheckStat(headMax int) *checkStat {
+	return &checkStat{
+		headMax: headMax,
+	}
+}
+
+func (s *checkStat) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	s.bytes += len(p)
+	s.newLines += bytes.Count(p, []byte{'\n'})
+	s.last = p[len(p)-1]
+	if n := s.headMax - len(s.head); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		s.head = append(s.head, p[:n]...)
+	}
+	s.checkUTF8(p)
+	return len(p), nil
+}
+
+// checkUTF8 validates p as the continuation of the previous writes.
+func (s *checkStat) checkUTF8(p []byte) {
+	if s.invalidUTF8 {
+		return
+	}
+	if len(s.pending) > 0 {
+		// 前回の途中の文字を補う.
+		i := 0
+		for ; i < len(p) && utf8.FullRune(s.pending) == false; i++ {
+			s.pending = append(s.pending, p[i])
+		}
+		if utf8.FullRune(s.pending) == false {
+			return
+		}
+		if r, size := utf8.DecodeRune(s.pending); r == utf8.RuneError && size <= 1 {
+			s.invalidUTF8 = true
+			return
+		}
+		s.pending = s.pending[:0]
+		p = p[i:]
+	}
+	tail := len(p)
+	for i := len(p) - 1; i >= 0 && i > len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if utf8.FullRune(p[i:]) == false {
+				tail = i
+			}
+			break
+		}
+	}
+	if utf8.Valid(p[:tail]) == false {
+		s.invalidUTF8 = true
+		return
+	}
+	s.pending = append(s.pending, p[tail:]...)
+}
+
+// lines returns the number of lines, the last line without newline is also counted.
+func (s *checkStat) lines() int {
+	if s.bytes > 0 && s.last != '\n' {
+		return s.newLines + 1
+	}
+	return s.newLines
+}
+
+// utf8Valid returns true if the written bytes are valid UTF-8(the incomplete last character is invalid).
+func (s *checkStat) utf8Valid() bool {
+	return s.invalidUTF8 == false && len(s.pending) == 0
+}
+
+// compileRegexps compiles the regular expressions.
+func compileRegexps(exprs []string) ([]*regexp.Regexp, error) {
+	ret := make([]*regexp.Regexp, 0, len(exprs))
+	for _, e := range exprs {
+		re, err := regexp.Compile(e)
+		if err != nil {
+			return nil, errors.Wrapf(err, "checkCli.Run compile regexp")
+		}
+		ret = append(ret, re)
+	}
+	return ret, nil
+}
+
+// buffered returns true if the predicates need the whole input.
+func (c *checkCli) buffered() bool {
+	return len(c.checkMatch) > 0 || len(c.checkNoMatch) > 0 || c.checkJSONValid
+}
+
+// satisfied returns true if the input satisfies all the predicates.
+//
+// b は buffered が true の場合のみ入力全体となる.
+func (c *checkCli) satisfied(stat *checkStat, b []byte, match []*regexp.Regexp, noMatch []*regexp.Regexp) bool {
+	if stat.bytes < c.checkMinBytes {
+		return false
+	}
+	if c.checkMinLines > 0 && stat.lines() < c.checkMinLines {
+		return false
+	}
+	for _, re := range match {
+		if re.Match(b) == false {
+			return false
+		}
+	}
+	for _, re := range noMatch {
+		if re.Match(b) {
+			return false
+		}
+	}
+	if c.checkJSONValid && json.Valid(b) == false {
+		return false
+	}
+	if c.checkUTF8Valid && stat.utf8Valid() == false {
+		return false
+	}
+	return true
+}
+
+func (c *checkCli) Run(ctx context.Context) (exitCode int, err error) {
+	switch c.checkReport {
+	case "", CheckReportJSON, CheckReportEnv:
+	default:
+		return CheckExitCodeError, fmt.Errorf("checkCli.Run invalid report format: %s", c.checkReport)
+	}
+	report := &checkReport{
+		InputType: inputType(c.inStream),
+	}
+	exitCode, err = c.check(ctx, report)
+	if c.checkReport == "" {
+		return exitCode, err
+	}
+
+	report.Passed = exitCode == 0
+	report.ExitCode = exitCode
+	w := c.errStream
+	var file *os.File
+	if c.checkReportFile != "" {
+		var ferr error
+		if file, ferr = os.Create(c.checkReportFile); ferr != nil {
+			return CheckExitCodeError, errors.Wrapf(ferr, "checkCli.Run create report file")
+		}
+		defer file.Close()
+		w = file
+	}
+	if werr := report.write(w, c.checkReport); werr != nil {
+		return CheckExitCodeError, errors.Wrapf(werr, "checkCli.Run writing the report")
+	}
+	if file != nil {
+		if cerr := file.Close(); cerr != nil {
+			return CheckExitCodeError, errors.Wrapf(cerr, "checkCli.Run writing the report")
+		}
+	}
+	return exitCode, err
+}
+
+// check checks the input and fills the report.
+func (c *checkCli) check(ctx context.Context, report *checkReport) (exitCode int, err error) {
+	match, err := compileRegexps(c.checkMatch)
+	if err != nil {
+		return CheckExitCodeError, err
+	}
+	noMatch, err := compileRegexps(c.checkNoMatch)
+	if err != nil {
+		return CheckExitCodeError, err
+	}
+
+	var reason tei.Reason
+	var sniffed []byte
+	sniffing := &countingReader{r: c.inStream}
+	var input io.Reader = sniffing
+	if report.InputType == "tty" || report.InputType == "null" {
+		// 端末の判定(*os.File)を妨げないように数えない(端末は sniff されない).
+		input = c.inStream
+	}
+	r, done, err := c.switchInput(ctx, c.teiBuilder.OnSwitch(func(rs tei.Reason, b []byte) {
+		reason = rs
+		sniffed = b
+		report.Reason = string(rs)
+	}).Standby(func() io.Reader {
+		return nil
+	}), input)
+	defer done()
+	if err != nil {
+		return CheckExitCodeError, errors.Wrapf(err, "checkCli.Run switching the input")
+	}
+	// 切り替えの判定(sniff)は switchInput の中で行われる.
+	report.SniffedBytes = sniffing.n
+
+	stat := newCheckStat(checkReportFirstLineMax)
+	if r == nil {
+		report.Empty = true
+		// データがない場合も(改行のみ等)入力をそのまま再現する.
+		// --json-path の場合は sniffed が入力の先頭部分のみとなるので、残りも続ける.
+		if err := c.read(switchedInput(reason, sniffed, c.inStream), report, stat, nil); err != nil {
+			return CheckExitCodeError, err
+		}
+		return CheckExitCodeFailed, nil
+	}
+
+	// 入力は読み込みながら pass-through する(predicate が必要とする場合のみ入力全体を保持する).
+	var buf *bytes.Buffer
+	if c.buffered() {
+		buf = &bytes.Buffer{}
+	}
+	if err := c.read(r, report, stat, buf); err != nil {
+		return CheckExitCodeError, err
+	}
+	var b []byte
+	if buf != nil {
+		b = buf.Bytes()
+	}
+	if c.satisfied(stat, b, match, noMatch) == false {
+		return CheckExitCodeFailed, nil
+	}
+	return 0, nil
+}
+
+// read reads the input to the end while counting it by stat(and passing it through), and fills the report.
+//
+// buf が nil でない場合は入力全体を保持する.
+func (c *checkCli) read(r io.Reader, report *checkReport, stat *checkStat, buf *bytes.Buffer) error {
+	p := make([]byte, checkReadSize)
+	for {
+		n, rerr := r.Read(p)
+		if n > 0 {
+			stat.Write(p[:n])
+			if buf != nil {
+				buf.Write(p[:n])
+			}
+			if c.checkPassThrough {
+				if _, err := c.outStream.Write(p[:n]); err != nil {
+					return errors.Wrapf(err, "checkCli.Run writing the input")
+				}
+				report.PassThrough = true
+			}
+		}
+		report.InputBytes = stat.bytes
+		report.FirstLine = firstLine(stat.head, checkReportFirstLineMax)
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return errors.Wrapf(rerr, "checkCli.Run reading the input")
+		}
+	}
+	if c.checkPassThrough {
+		report.PassThrough = true
+	}
+	return nil
+}
+
+func newCheckCli(b *builder) *checkCli {
+	return &checkCli{
+		baseCli:          *newBaseCli(b),
+		checkMinBytes:    b.checkMinBytes,
+		checkMinLines:    b.checkMinLines,
+		checkMatch:       b.checkMatch,
+		checkNoMatch:     b.checkNoMatch,
+		checkJSONValid:   b.checkJSONValid,
+		checkUTF8Valid:   b.checkUTF8Valid,
+		checkPassThrough: b.checkPassThrough,
+		checkReport:      b.checkReport,
+		checkReportFile:  b.checkReportFile,
+	}
+}
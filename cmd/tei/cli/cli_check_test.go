@@ -0,0 +1,569 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkCli_Run(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Check(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "no data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Check(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				Check(true).
+				CheckPassThrough(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "pass through",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data\n")).
+				Check(true).
+				CheckPassThrough(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data\n",
+		}, {
+			name: "pass through newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Check(true).
+				CheckPassThrough(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "\n",
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "pass through json path",
+			builder: NewBuilder().
+				TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+				InStream(strings.NewReader(`{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`)).
+				Check(true).
+				CheckPassThrough(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         `{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`,
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "pass through failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data\n")).
+				Check(true).
+				CheckMinBytes(100).
+				CheckPassThrough(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "input data\n",
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "min bytes",
+			builder: NewBuilder().
+				InStream(strings.NewReader("12345")).
+				Check(true).
+				CheckMinBytes(5),
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "min bytes failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("1234")).
+				Check(true).
+				CheckMinBytes(5),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "min lines",
+			builder: NewBuilder().
+				InStream(strings.NewReader("line1\nline2")).
+				Check(true).
+				CheckMinLines(2),
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "min lines failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("line1\n")).
+				Check(true).
+				CheckMinLines(2),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "match",
+			builder: NewBuilder().
+				InStream(strings.NewReader("status: ok\ncount: 10\n")).
+				Check(true).
+				CheckMatch([]string{`(?m)^status: ok$`, `count: \d+`}).
+				CheckNoMatch([]string{`error`}),
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "match failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("status: ok\n")).
+				Check(true).
+				CheckMatch([]string{`(?m)^status: ok$`, `count: \d+`}),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "no match failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("status: error\n")).
+				Check(true).
+				CheckNoMatch([]string{`error`}),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "invalid regexp",
+			builder: NewBuilder().
+				InStream(strings.NewReader("status: ok\n")).
+				Check(true).
+				CheckMatch([]string{`(`}),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeError,
+			wantErr:      true,
+		}, {
+			name: "json valid",
+			builder: NewBuilder().
+				InStream(strings.NewReader(`{"a": [1, 2]}` + "\n")).
+				Check(true).
+				CheckJSONValid(true),
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "json valid failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader(`{"a": [1, 2]`)).
+				Check(true).
+				CheckJSONValid(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "utf8 valid",
+			builder: NewBuilder().
+				InStream(strings.NewReader("日本語\n")).
+				Check(true).
+				CheckUTF8Valid(true),
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "utf8 valid failed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\xff\xfe\xfd")).
+				Check(true).
+				CheckUTF8Valid(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "error",
+			builder: NewBuilder().
+				InStream(tei.ErrReader(errors.New("test error"))).
+				Check(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeError,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("checkCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "checkCli.Run() outStream")
+		})
+	}
+}
+
+func Test_checkCli_Run_report(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	reportFile := filepath.Join(dir, "report.env")
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantReport   string
+		wantFile     string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "json",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data\nline2\n")).
+				Check(true).
+				CheckReport(CheckReportJSON),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantReport: `{"empty":false,"reason":"","sniffed_bytes":3,"input_bytes":17,"first_line":"input data","input_type":"other","pass_through":false,"passed":true,"exit_code":0}` + "\n",
+		}, {
+			name: "json newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Check(true).
+				CheckPassThrough(true).
+				CheckReport(CheckReportJSON),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "\n",
+			wantReport:   `{"empty":true,"reason":"newline","sniffed_bytes":1,"input_bytes":1,"first_line":"","input_type":"other","pass_through":true,"passed":false,"exit_code":1}` + "\n",
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "env",
+			builder: NewBuilder().
+				InStream(strings.NewReader("it's data\n")).
+				Check(true).
+				CheckMinLines(2).
+				CheckPassThrough(true).
+				CheckReport(CheckReportEnv),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "it's data\n",
+			wantReport: `TEI_EMPTY=false
+TEI_REASON=''
+TEI_SNIFFED_BYTES=3
+TEI_INPUT_BYTES=10
+TEI_FIRST_LINE='it'\''s data'
+TEI_INPUT_TYPE='other'
+TEI_PASS_THROUGH=true
+TEI_PASSED=false
+TEI_EXIT_CODE=1
+`,
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "env null",
+			builder: NewBuilder().
+				InStream(func() io.Reader {
+					f, _ := os.Open(os.DevNull)
+					return f
+				}()).
+				Check(true).
+				CheckReport(CheckReportEnv),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantReport: `TEI_EMPTY=true
+TEI_REASON='terminal'
+TEI_SNIFFED_BYTES=0
+TEI_INPUT_BYTES=0
+TEI_FIRST_LINE=''
+TEI_INPUT_TYPE='null'
+TEI_PASS_THROUGH=false
+TEI_PASSED=false
+TEI_EXIT_CODE=1
+`,
+			wantExitCode: CheckExitCodeFailed,
+		}, {
+			name: "file",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Check(true).
+				CheckReport(CheckReportEnv).
+				CheckReportFile(reportFile),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantFile: `TEI_EMPTY=false
+TEI_REASON=''
+TEI_SNIFFED_BYTES=3
+TEI_INPUT_BYTES=10
+TEI_FIRST_LINE='input data'
+TEI_INPUT_TYPE='other'
+TEI_PASS_THROUGH=false
+TEI_PASSED=true
+TEI_EXIT_CODE=0
+`,
+		}, {
+			name: "error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Check(true).
+				CheckMatch([]string{"("}).
+				CheckReport(CheckReportJSON),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantReport:   `{"empty":false,"reason":"","sniffed_bytes":0,"input_bytes":0,"first_line":"","input_type":"other","pass_through":false,"passed":false,"exit_code":2}` + "\n",
+			wantExitCode: CheckExitCodeError,
+			wantErr:      true,
+		}, {
+			name: "invalid format",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Check(true).
+				CheckReport("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			wantExitCode: CheckExitCodeError,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(errStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if f, ok := c.InStream().(*os.File); ok {
+				f.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("checkCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "checkCli.Run() outStream")
+			assert.Equal(t, tt.wantReport, errStream.String(), "checkCli.Run() errStream")
+			if tt.wantFile != "" {
+				b, err := ioutil.ReadFile(reportFile)
+				assert.Nil(t, err, "checkCli.Run() report file")
+				assert.Equal(t, tt.wantFile, string(b), "checkCli.Run() report file")
+			}
+		})
+	}
+}
+
+func Test_checkCli_Run_report_json_path(t *testing.T) {
+	input := `{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`
+	errStream := &strings.Builder{}
+	exitCode, err := NewBuilder().
+		TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+		InStream(strings.NewReader(input)).
+		OutStream(ioutil.Discard).
+		ErrStream(errStream).
+		Check(true).
+		CheckReport(CheckReportJSON).
+		Build().
+		Run(context.Background())
+	assert.Nil(t, err, "checkCli.Run() err")
+	assert.Equal(t, CheckExitCodeFailed, exitCode, "checkCli.Run() exitCode")
+
+	// sniff は対象のノードを判定した時点で終わる.
+	report := &checkReport{}
+	assert.Nil(t, json.Unmarshal([]byte(errStream.String()), report), "checkCli.Run() report")
+	assert.Equal(t, len(input), report.InputBytes, "checkCli.Run() report input_bytes")
+	assert.True(t, report.SniffedBytes > 0 && report.SniffedBytes < len(input), "checkCli.Run() report sniffed_bytes")
+}
+
+func Test_firstLine(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		max  int
+		want string
+	}{
+		{name: "basic", b: []byte("line1\nline2\n"), max: 80, want: "line1"},
+		{name: "crlf", b: []byte("line1\r\nline2\r\n"), max: 80, want: "line1"},
+		{name: "no newline", b: []byte("line1"), max: 80, want: "line1"},
+		{name: "empty", b: []byte{}, max: 80, want: ""},
+		{name: "truncate", b: []byte("line1\n"), max: 3, want: "lin"},
+		{name: "truncate utf8", b: []byte("日本語\n"), max: 5, want: "日"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, firstLine(tt.b, tt.max), "firstLine()")
+		})
+	}
+}
+
+func Test_inputType(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.Close()
+	defer pw.Close()
+	file, err := os.Open(testStandbyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer null.Close()
+
+	tests := []struct {
+		name string
+		r    io.Reader
+		want string
+	}{
+		{name: "pipe", r: pr, want: "pipe"},
+		{name: "file", r: file, want: "file"},
+		{name: "null", r: null, want: "null"},
+		{name: "other", r: strings.NewReader(""), want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, inputType(tt.r), "inputType()")
+		})
+	}
+}
+
+func Test_checkStat(t *testing.T) {
+	tests := []struct {
+		name          string
+		chunks        []string
+		wantBytes     int
+		wantLines     int
+		wantHead      string
+		wantUTF8Valid bool
+	}{
+		{name: "basic", chunks: []string{"line1\nline2"}, wantBytes: 11, wantLines: 2, wantHead: "line1", wantUTF8Valid: true},
+		{name: "chunks", chunks: []string{"line1\n", "line2\n"}, wantBytes: 12, wantLines: 2, wantHead: "line1", wantUTF8Valid: true},
+		{name: "head", chunks: []string{"1234", "5678"}, wantBytes: 8, wantLines: 1, wantHead: "12345", wantUTF8Valid: true},
+		{name: "utf8 split", chunks: []string{"日\xe6", "\x9c", "\xac語"}, wantBytes: 9, wantLines: 1, wantHead: "日\xe6\x9c", wantUTF8Valid: true},
+		{name: "utf8 incomplete", chunks: []string{"日\xe6\x9c"}, wantBytes: 5, wantLines: 1, wantHead: "日\xe6\x9c", wantUTF8Valid: false},
+		{name: "utf8 invalid split", chunks: []string{"日\xe6", "a"}, wantBytes: 5, wantLines: 1, wantHead: "日\xe6a", wantUTF8Valid: false},
+		{name: "utf8 invalid", chunks: []string{"ok", "\xff\xfe\xfd"}, wantBytes: 5, wantLines: 1, wantHead: "ok\xff\xfe\xfd", wantUTF8Valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCheckStat(5)
+			for _, c := range tt.chunks {
+				s.Write([]byte(c))
+			}
+			assert.Equal(t, tt.wantBytes, s.bytes, "checkStat bytes")
+			assert.Equal(t, tt.wantLines, s.lines(), "checkStat.lines()")
+			assert.Equal(t, tt.wantHead, string(s.head), "checkStat head")
+			assert.Equal(t, tt.wantUTF8Valid, s.utf8Valid(), "checkStat.utf8Valid()")
+		})
+	}
+}
+
+func Test_checkCli_Run_stream(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := NewBuilder().
+		InStream(inR).
+		OutStream(outW).
+		ErrStream(ioutil.Discard).
+		Check(true).
+		CheckMinLines(2).
+		CheckPassThrough(true).
+		Build()
+	type result struct {
+		exitCode int
+		err      error
+	}
+	resultCh := make(chan result)
+	go func() {
+		exitCode, err := c.Run(context.Background())
+		outW.Close()
+		resultCh <- result{exitCode: exitCode, err: err}
+	}()
+
+	// EOF の前に pass-through される.
+	go func() {
+		inW.Write([]byte("line1\n"))
+	}()
+	p := make([]byte, 6)
+	n, err := io.ReadFull(outR, p)
+	assert.Nil(t, err, "checkCli.Run() outStream before EOF")
+	assert.Equal(t, "line1\n", string(p[:n]), "checkCli.Run() outStream before EOF")
+
+	go func() {
+		inW.Write([]byte("line2\n"))
+		inW.Close()
+	}()
+	b, err := ioutil.ReadAll(outR)
+	assert.Nil(t, err, "checkCli.Run() outStream")
+	assert.Equal(t, "line2\n", string(b), "checkCli.Run() outStream")
+	res := <-resultCh
+	assert.Nil(t, res.err, "checkCli.Run() err")
+	assert.Equal(t, 0, res.exitCode, "checkCli.Run() exitCode")
+}
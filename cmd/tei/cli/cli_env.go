@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hankei6km/go-tei"
+)
+
+type envCli struct {
+	baseCli
+	envVar         string
+	envVarNewline  bool
+	envVarRequired bool
+}
+
+// EnvCliBuilder adds a property to CliBuilder.
+type EnvCliBuilder interface {
+	// EnvVar sets the name of the environment variable that is used as the standby source.
+	EnvVar(string) Builder
+	// EnvVarNewline sets the flag that a newline is added to the value.
+	EnvVarNewline(bool) Builder
+	// EnvVarRequired sets the flag that the unset variable is an error.
+	EnvVarRequired(bool) Builder
+}
+
+func (c *envCli) Run(ctx context.Context) (exitCode int, err error) {
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		v, ok := os.LookupEnv(c.envVar)
+		if ok == false {
+			if c.envVarRequired {
+				return tei.ErrReader(fmt.Errorf("envCli.Run the variable is unset: %s", c.envVar))
+			}
+			return strings.NewReader("")
+		}
+		if c.envVarNewline {
+			v = v + NewLineString
+		}
+		return strings.NewReader(v)
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newEnvCli(b *builder) *envCli {
+	return &envCli{
+		baseCli:        *newBaseCli(b),
+		envVar:         b.envVar,
+		envVarNewline:  b.envVarNewline,
+		envVarRequired: b.envVarRequired,
+	}
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_envCli_Run(t *testing.T) {
+	os.Setenv("TEI_TEST_ENV_CLI", "-n env data")
+	os.Setenv("TEI_TEST_ENV_CLI_EMPTY", "")
+	os.Unsetenv("TEI_TEST_ENV_CLI_UNSET")
+	defer func() {
+		os.Unsetenv("TEI_TEST_ENV_CLI")
+		os.Unsetenv("TEI_TEST_ENV_CLI_EMPTY")
+	}()
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				EnvVar("TEI_TEST_ENV_CLI"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				EnvVar("TEI_TEST_ENV_CLI"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "-n env data",
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				EnvVar("TEI_TEST_ENV_CLI").
+				EnvVarNewline(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "-n env data\n",
+		}, {
+			name: "empty",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				EnvVar("TEI_TEST_ENV_CLI_EMPTY").
+				EnvVarNewline(true).
+				EnvVarRequired(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "\n",
+		}, {
+			name: "unset",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				EnvVar("TEI_TEST_ENV_CLI_UNSET").
+				EnvVarNewline(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "unset required",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				EnvVar("TEI_TEST_ENV_CLI_UNSET").
+				EnvVarRequired(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("envCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("envCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "envCli.Run() outStream")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+type fdCli struct {
+	baseCli
+	fd int
+}
+
+// FdCliBuilder adds a property to CliBuilder.
+type FdCliBuilder interface {
+	// Fd sets the file descriptor that is already opened as the standby source.
+	Fd(int) Builder
+}
+
+func (c *fdCli) Run(ctx context.Context) (exitCode int, err error) {
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		file = os.NewFile(uintptr(c.fd), fmt.Sprintf("fd%d", c.fd))
+		if file == nil {
+			return tei.ErrReader(fmt.Errorf("fdCli.Run invalid fd: %d", c.fd))
+		}
+		if _, err := file.Stat(); err != nil {
+			f := file
+			file = nil // 開いていない fd は閉じない.
+			return tei.ErrReader(errors.Wrapf(err, "fdCli.Run stat fd(%s)", f.Name()))
+		}
+		return file
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newFdCli(b *builder) *fdCli {
+	return &fdCli{
+		baseCli: *newBaseCli(b),
+		fd:      b.fd,
+	}
+}
@@ -0,0 +1,108 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStandbyFd returns the file descriptor of the standby file,
+// the descriptor is owned by the caller(fdCli closes it).
+func testStandbyFd() int {
+	file, err := os.Open(testStandbyFile())
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	fd, err := syscall.Dup(int(file.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	return fd
+}
+
+func Test_fdCli_Run(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      func() Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: func() Builder {
+				return NewBuilder().
+					InStream(strings.NewReader("input data")).
+					Fd(9999)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: func() Builder {
+				return NewBuilder().
+					InStream(strings.NewReader("")).
+					Fd(testStandbyFd())
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\n",
+		}, {
+			name: "stdin",
+			builder: func() Builder {
+				return NewBuilder().
+					InStream(os.Stdin).
+					Fd(testStandbyFd())
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\n",
+		}, {
+			name: "bad fd",
+			builder: func() Builder {
+				return NewBuilder().
+					InStream(strings.NewReader("")).
+					Fd(9999)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder().
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fdCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("fdCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "fdCli.Run() outStream")
+		})
+	}
+}
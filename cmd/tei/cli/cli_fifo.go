@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+type fifoCli struct {
+	baseCli
+	fifo        string
+	fifoTimeout time.Duration
+}
+
+// FifoCliBuilder adds a property to CliBuilder.
+type FifoCliBuilder interface {
+	// Fifo sets the path of the named pipe that is used as the standby source.
+	Fifo(string) Builder
+	// FifoTimeout sets the timeout to wait for the writer while opening the named pipe(0 is no timeout).
+	FifoTimeout(time.Duration) Builder
+}
+
+// openFifo opens the named pipe for reading within the timeout.
+//
+// 読み込み側の open は書き込み側が open するまでブロックするので、
+// タイムアウトした場合は自身で書き込み側を開いてブロックを解除する.
+func openFifo(ctx context.Context, name string, timeout time.Duration) (*os.File, error) {
+	type result struct {
+		file *os.File
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		file, err := os.OpenFile(name, os.O_RDONLY, 0)
+		ch <- result{file: file, err: err}
+	}()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	var cause error
+	select {
+	case r := <-ch:
+		return r.file, r.err
+	case <-timeoutCh:
+		cause = fmt.Errorf("timeout(%s)", timeout)
+	case <-ctx.Done():
+		cause = ctx.Err()
+	}
+
+	w, err := os.OpenFile(name, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err == nil {
+		w.Close()
+	}
+	r := <-ch
+	if r.file != nil {
+		r.file.Close()
+	}
+	return nil, errors.Wrapf(cause, "openFifo open %s", name)
+}
+
+func (c *fifoCli) Run(ctx context.Context) (exitCode int, err error) {
+	var file *os.File
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		f, err := openFifo(ctx, c.fifo, c.fifoTimeout)
+		if err != nil {
+			return tei.ErrReader(errors.Wrapf(err, "fifoCli.Run open fifo"))
+		}
+		file = f
+		return file
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newFifoCli(b *builder) *fifoCli {
+	return &fifoCli{
+		baseCli:     *newBaseCli(b),
+		fifo:        b.fifo,
+		fifoTimeout: b.fifoTimeout,
+	}
+}
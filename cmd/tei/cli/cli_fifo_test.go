@@ -0,0 +1,127 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFifo(t *testing.T) (fifo string, removeFunc func()) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fifo = filepath.Join(dir, "test.fifo")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return fifo, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func Test_fifoCli_Run(t *testing.T) {
+	fifo, removeFunc := testFifo(t)
+	defer removeFunc()
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		writeData    string
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Fifo(fifo).
+				FifoTimeout(100 * time.Millisecond),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Fifo(fifo),
+			writeData: "fifo data\n",
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "fifo data\n",
+		}, {
+			name: "timeout",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Fifo(fifo).
+				FifoTimeout(100 * time.Millisecond),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Fifo(fifo + ".not_exist"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			if tt.writeData != "" {
+				go func() {
+					defer close(done)
+					w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+					if err != nil {
+						return
+					}
+					w.Write([]byte(tt.writeData))
+					w.Close()
+				}()
+			} else {
+				close(done)
+			}
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			<-done
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fifoCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("fifoCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "fifoCli.Run() outStream")
+		})
+	}
+}
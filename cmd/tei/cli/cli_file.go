@@ -2,37 +2,250 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/internal/errors"
 )
 
+const (
+	// FileMissingError is that the missing(or unreadable) file is an error.
+	FileMissingError = "error"
+	// FileMissingSkip is that the missing(or unreadable) file is skipped.
+	FileMissingSkip = "skip"
+)
+
+const (
+	// FileSelectFirst is that each matched file is used as the candidate in lexical order.
+	FileSelectFirst = "first"
+	// FileSelectNewest is that the newest matched file(by mtime) is used.
+	FileSelectNewest = "newest"
+	// FileSelectOldest is that the oldest matched file(by mtime) is used.
+	FileSelectOldest = "oldest"
+	// FileSelectLast is that the lexically last matched file is used.
+	FileSelectLast = "last"
+	// FileSelectAll is that all the matched files are concatenated.
+	FileSelectAll = "all"
+)
+
+const (
+	// FileStaleWarn is that the warning is written to the error stream if the file is stale.
+	FileStaleWarn = "warn"
+	// FileStaleError is that the stale file is an error(exit code is ExitCodeStale).
+	FileStaleError = "error"
+	// FileStaleSkip is that the stale file is skipped.
+	FileStaleSkip = "skip"
+)
+
+// ExitCodeStale is the exit code of Cli.Run if the standby file is stale.
+const ExitCodeStale = 3
+
 type fileCli struct {
 	baseCli
-	file string
+	files   []string
+	missing string
+	concat  bool
+	sel     string
+	maxAge  time.Duration
+	stale   string
 }
 
 // FileCliBuilder adds a property to CliBuilder.
 type FileCliBuilder interface {
 	File(string) Builder
+	// Files sets the files(or glob patterns), the first one that has data is used.
+	Files([]string) Builder
+	// Missing sets the policy for the missing file(FileMissingError, FileMissingSkip).
+	Missing(string) Builder
+	// Concat sets the flag that all the files are concatenated.
+	Concat(bool) Builder
+	// Select sets the strategy to select the files matched by the glob pattern
+	// (FileSelectFirst, FileSelectNewest, FileSelectOldest, FileSelectLast, FileSelectAll).
+	Select(string) Builder
+	// MaxAge sets the age(by mtime) that the file is treated as stale(0 is disabled).
+	MaxAge(time.Duration) Builder
+	// Stale sets the policy for the stale file(FileStaleWarn, FileStaleError, FileStaleSkip).
+	Stale(string) Builder
+}
+
+// missingErr returns the error by the missing policy, returns nil if the file is skipped.
+func (c *fileCli) missingErr(err error) error {
+	switch c.missing {
+	case "", FileMissingError:
+		return err
+	case FileMissingSkip:
+		return nil
+	}
+	return fmt.Errorf("fileCli.Run invalid missing policy: %s", c.missing)
+}
+
+// checkStale checks the age of the opened file, returns false if the file is skipped.
+func (c *fileCli) checkStale(file *os.File) (bool, error) {
+	if c.maxAge <= 0 {
+		return true, nil
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return false, errors.Wrapf(err, "fileCli.Run stat file")
+	}
+	if time.Since(stat.ModTime()) <= c.maxAge {
+		return true, nil
+	}
+	switch c.stale {
+	case "", FileStaleWarn:
+		fmt.Fprintf(c.errStream, "Warning in fileCli: the file is stale: %s\n", file.Name())
+		return true, nil
+	case FileStaleError:
+		return false, &exitCodeError{
+			exitCode: ExitCodeStale,
+			err:      fmt.Errorf("fileCli.Run the file is stale: %s", file.Name()),
+		}
+	case FileStaleSkip:
+		return false, nil
+	}
+	return false, fmt.Errorf("fileCli.Run invalid stale policy: %s", c.stale)
+}
+
+// candidates expands the glob pattern and selects the files by the strategy.
+//
+// 戻り値の各要素が 1 つの候補(複数ファイルの場合は連結される).
+// パターンにマッチするファイルがない場合は missing の扱いになる.
+func (c *fileCli) candidates(pattern string) ([][]string, error) {
+	if strings.ContainsAny(pattern, "*?[") == false {
+		return [][]string{{pattern}}, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fileCli.Run glob pattern(%s)", pattern)
+	}
+	if len(matches) == 0 {
+		return nil, c.missingErr(fmt.Errorf("fileCli.Run no files match pattern: %s", pattern))
+	}
+
+	switch c.sel {
+	case "", FileSelectFirst:
+		ret := make([][]string, 0, len(matches))
+		for _, m := range matches {
+			ret = append(ret, []string{m})
+		}
+		return ret, nil
+	case FileSelectNewest, FileSelectOldest:
+		selected := ""
+		var selectedStat os.FileInfo
+		for _, m := range matches {
+			stat, err := os.Stat(m)
+			if err != nil {
+				continue
+			}
+			if selectedStat == nil ||
+				(c.sel == FileSelectNewest && stat.ModTime().After(selectedStat.ModTime())) ||
+				(c.sel == FileSelectOldest && stat.ModTime().Before(selectedStat.ModTime())) {
+				selected = m
+				selectedStat = stat
+			}
+		}
+		if selected == "" {
+			return nil, c.missingErr(fmt.Errorf("fileCli.Run no files match pattern: %s", pattern))
+		}
+		return [][]string{{selected}}, nil
+	case FileSelectLast:
+		return [][]string{{matches[len(matches)-1]}}, nil
+	case FileSelectAll:
+		return [][]string{matches}, nil
+	}
+	return nil, fmt.Errorf("fileCli.Run invalid select: %s", c.sel)
 }
 
 func (c *fileCli) Run(ctx context.Context) (exitCode int, err error) {
-	var file *os.File
+	opened := []*os.File{}
 	defer func() {
-		if file != nil {
+		for _, file := range opened {
 			file.Close()
 		}
 	}()
+
+	// open は候補のファイルを開く、全てスキップされた場合は nil を返す.
+	open := func(names []string) (io.Reader, error) {
+		readers := []io.Reader{}
+		for _, name := range names {
+			file, err := os.Open(name)
+			if err != nil {
+				if err := c.missingErr(errors.Wrapf(err, "fileCli.Run open file")); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			opened = append(opened, file)
+			ok, err := c.checkStale(file)
+			if err != nil {
+				return nil, err
+			}
+			if ok == false {
+				continue
+			}
+			readers = append(readers, file)
+		}
+		switch len(readers) {
+		case 0:
+			return nil, nil
+		case 1:
+			return readers[0], nil
+		}
+		return io.MultiReader(readers...), nil
+	}
+
 	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
-		file, err = os.Open(c.file)
-		if err != nil {
-			file = nil
-			return tei.ErrReader(errors.Wrapf(err, "fileCli.Run open file"))
+		candidates := [][]string{}
+		for _, pattern := range c.files {
+			ca, err := c.candidates(pattern)
+			if err != nil {
+				return tei.ErrReader(err)
+			}
+			candidates = append(candidates, ca...)
+		}
+
+		if c.concat {
+			readers := []io.Reader{}
+			for _, names := range candidates {
+				r, err := open(names)
+				if err != nil {
+					return tei.ErrReader(err)
+				}
+				if r != nil {
+					readers = append(readers, r)
+				}
+			}
+			return io.MultiReader(readers...)
+		}
+
+		// standby は i 番目以降の候補で最初にデータがあるものを返す.
+		// 最後の候補は空であってもそのまま返す(ファイルが 1 つの場合と同じ挙動).
+		var standby func(i int) io.Reader
+		standby = func(i int) io.Reader {
+			for ; i < len(candidates); i++ {
+				r, err := open(candidates[i])
+				if err != nil {
+					return tei.ErrReader(err)
+				}
+				if r == nil {
+					continue
+				}
+				if i == len(candidates)-1 {
+					return r
+				}
+				next := i + 1
+				return c.teiBuilder.OnSwitch(nil).Standby(func() io.Reader {
+					return standby(next)
+				}).Build().Switch(r)
+			}
+			return strings.NewReader("")
 		}
-		return file
+		return standby(0)
 	})
 	return c.baseCli.Run(ctx)
 }
@@ -40,6 +253,11 @@ func (c *fileCli) Run(ctx context.Context) (exitCode int, err error) {
 func newFileCli(b *builder) *fileCli {
 	return &fileCli{
 		baseCli: *newBaseCli(b),
-		file:    b.file,
+		files:   b.files,
+		missing: b.missing,
+		concat:  b.concat,
+		sel:     b.sel,
+		maxAge:  b.maxAge,
+		stale:   b.stale,
 	}
 }
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -60,13 +62,422 @@ func Test_fileCli_Run(t *testing.T) {
 			want:         "",
 			wantExitCode: 1,
 			wantErr:      true,
+		}, {
+			name: "files",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testStandbyFile(), testStandbyFile2()}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\n",
+		}, {
+			name: "files empty",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testDummyFile(), testNewlineFile(), testStandbyFile2(), testStandbyFile()}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data 2\n",
+		}, {
+			name: "files last empty",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testDummyFile(), testNewlineFile()}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "\n",
+		}, {
+			name: "files not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testDummyFile(), filepath.Join(testDummyFile(), "not_exist"), testStandbyFile()}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "files missing skip",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{filepath.Join(testDummyFile(), "not_exist"), testDummyFile(), testStandbyFile()}).
+				Missing(FileMissingSkip),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\n",
+		}, {
+			name: "files missing skip all",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{filepath.Join(testDummyFile(), "not_exist")}).
+				Missing(FileMissingSkip),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "files missing invalid",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{filepath.Join(testDummyFile(), "not_exist")}).
+				Missing("invalid"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "concat",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testStandbyFile(), testDummyFile(), testStandbyFile2()}).
+				Concat(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\nfile data 2\n",
+		}, {
+			name: "concat data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Files([]string{testStandbyFile(), testDummyFile(), testStandbyFile2()}).
+				Concat(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "concat not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testStandbyFile(), filepath.Join(testDummyFile(), "not_exist"), testStandbyFile2()}).
+				Concat(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "concat missing skip",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Files([]string{testStandbyFile(), filepath.Join(testDummyFile(), "not_exist"), testStandbyFile2()}).
+				Missing(FileMissingSkip).
+				Concat(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\nfile data 2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fileCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("fileCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "fileCli.Run() outStream")
+		})
+	}
+}
+
+func Test_fileCli_Run_glob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	for i, f := range []struct {
+		name    string
+		content string
+		mtime   time.Time
+	}{
+		{name: "snap-1.txt", content: "snap 1\n", mtime: now.Add(-3 * time.Hour)},
+		{name: "snap-2.txt", content: "snap 2\n", mtime: now.Add(-1 * time.Hour)},
+		{name: "snap-3.txt", content: "snap 3\n", mtime: now.Add(-2 * time.Hour)},
+		{name: "empty-1.txt", content: "", mtime: now},
+		{name: "empty-2.txt", content: "\n", mtime: now},
+	} {
+		name := filepath.Join(dir, f.name)
+		if err := ioutil.WriteFile(name, []byte(f.content), 0644); err != nil {
+			t.Fatal(i, err)
+		}
+		if err := os.Chtimes(name, f.mtime, f.mtime); err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "first",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 1\n",
+		}, {
+			name: "first empty",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "empty-*.txt"), filepath.Join(dir, "snap-*.txt")}).
+				Select(FileSelectFirst),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 1\n",
+		}, {
+			name: "newest",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}).
+				Select(FileSelectNewest),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 2\n",
+		}, {
+			name: "oldest",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}).
+				Select(FileSelectOldest),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 1\n",
+		}, {
+			name: "last",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}).
+				Select(FileSelectLast),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 3\n",
+		}, {
+			name: "all",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}).
+				Select(FileSelectAll),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 1\nsnap 2\nsnap 3\n",
+		}, {
+			name: "concat",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-[12].txt"), filepath.Join(dir, "snap-3.txt")}).
+				Concat(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 1\nsnap 2\nsnap 3\n",
+		}, {
+			name: "no match",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "none-*.txt"), filepath.Join(dir, "snap-*.txt")}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "no match skip",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "none-*.txt"), filepath.Join(dir, "snap-*.txt")}).
+				Missing(FileMissingSkip).
+				Select(FileSelectNewest),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "snap 2\n",
+		}, {
+			name: "bad pattern",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-[.txt")}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "invalid select",
+			builder: NewBuilder().
+				Files([]string{filepath.Join(dir, "snap-*.txt")}).
+				Select("invalid"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				InStream(strings.NewReader("")).
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fileCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("fileCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "fileCli.Run() outStream")
+		})
+	}
+}
+
+func Test_fileCli_Run_stale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	now := time.Now()
+	staleFile := filepath.Join(dir, "stale.txt")
+	freshFile := filepath.Join(dir, "fresh.txt")
+	for i, f := range []struct {
+		name    string
+		content string
+		mtime   time.Time
+	}{
+		{name: staleFile, content: "stale\n", mtime: now.Add(-2 * time.Hour)},
+		{name: freshFile, content: "fresh\n", mtime: now},
+	} {
+		if err := ioutil.WriteFile(f.name, []byte(f.content), 0644); err != nil {
+			t.Fatal(i, err)
+		}
+		if err := os.Chtimes(f.name, f.mtime, f.mtime); err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantErrOut   string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "fresh",
+			builder: NewBuilder().
+				Files([]string{freshFile}).
+				MaxAge(time.Hour),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "fresh\n",
+		}, {
+			name: "disabled",
+			builder: NewBuilder().
+				Files([]string{staleFile}).
+				Stale(FileStaleError),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "stale\n",
+		}, {
+			name: "warn",
+			builder: NewBuilder().
+				Files([]string{staleFile}).
+				MaxAge(time.Hour),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       "stale\n",
+			wantErrOut: "Warning in fileCli: the file is stale: " + staleFile + "\n",
+		}, {
+			name: "error",
+			builder: NewBuilder().
+				Files([]string{staleFile, freshFile}).
+				MaxAge(time.Hour).
+				Stale(FileStaleError),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: ExitCodeStale,
+			wantErr:      true,
+		}, {
+			name: "skip",
+			builder: NewBuilder().
+				Files([]string{staleFile, freshFile}).
+				MaxAge(time.Hour).
+				Stale(FileStaleSkip),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "fresh\n",
+		}, {
+			name: "skip all",
+			builder: NewBuilder().
+				Files([]string{staleFile}).
+				MaxAge(time.Hour).
+				Stale(FileStaleSkip),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "invalid",
+			builder: NewBuilder().
+				Files([]string{staleFile}).
+				MaxAge(time.Hour).
+				Stale("invalid"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
 			c := tt.builder.
+				InStream(strings.NewReader("")).
 				OutStream(outStream).
+				ErrStream(errStream).
 				Build()
 			gotExitCode, err := c.Run(tt.args.ctx)
 			if (err != nil) != tt.wantErr {
@@ -77,6 +488,7 @@ func Test_fileCli_Run(t *testing.T) {
 				t.Errorf("fileCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
 			}
 			assert.Equal(t, tt.want, outStream.String(), "fileCli.Run() outStream")
+			assert.Equal(t, tt.wantErrOut, errStream.String(), "fileCli.Run() errStream")
 		})
 	}
 }
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os/exec"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// ifDataCli runs the command with the input only if the input has data.
+//
+// runCli のプロセス制御(chdir や env)を再利用するため runCli を埋め込んでいる.
+type ifDataCli struct {
+	runCli
+	ifDataCmdArgs []string
+	noDataCmdArgs []string
+}
+
+// IfDataCliBuilder adds a property to CliBuilder.
+type IfDataCliBuilder interface {
+	// IfDataCmdArgs sets the command that is run with the input if the input has data.
+	IfDataCmdArgs([]string) Builder
+	// NoDataCmdArgs sets the command that is run if no data from the input.
+	NoDataCmdArgs([]string) Builder
+}
+
+func (c *ifDataCli) Run(ctx context.Context) (exitCode int, err error) {
+	var reason tei.Reason
+	var sniffed []byte
+	inStream, done, err := c.switchInput(ctx, c.teiBuilder.OnSwitch(func(r tei.Reason, b []byte) {
+		reason = r
+		sniffed = b
+	}).Standby(func() io.Reader {
+		return nil
+	}), c.inStream)
+	defer done()
+	if err != nil {
+		return errExitCode(err), errors.Wrapf(err, "ifDataCli.Run switching the input")
+	}
+
+	cmdArgs := c.ifDataCmdArgs
+	if inStream == nil {
+		cmdArgs = c.noDataCmdArgs
+	}
+	if len(cmdArgs) == 0 {
+		return 0, nil
+	}
+
+	cmd, err := c.command(ctx, cmdArgs, reason, sniffed)
+	if err != nil {
+		return 1, errors.Wrapf(err, "ifDataCli.Run command args(%s)", cmdArgs)
+	}
+	if inStream != nil {
+		cmd.Stdin = inStream
+	} else {
+		stdin, err := c.stdin(reason, sniffed)
+		if err != nil {
+			return 1, errors.Wrapf(err, "ifDataCli.Run stdin args(%s)", cmdArgs)
+		}
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = c.outStream
+	cmd.Stderr = c.errStream
+	if err := cmd.Start(); err != nil {
+		return 1, errors.Wrapf(err, "ifDataCli.Run start args(%s)", cmdArgs)
+	}
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() >= 0 {
+			// コマンドの終了コードをそのまま伝播させる.
+			return exitErr.ExitCode(), nil
+		}
+		return 1, errors.Wrapf(err, "ifDataCli.Run wait args(%s)", cmdArgs)
+	}
+	return 0, nil
+}
+
+func newIfDataCli(b *builder) *ifDataCli {
+	return &ifDataCli{
+		runCli:        *newRunCli(b),
+		ifDataCmdArgs: b.ifDataCmdArgs,
+		noDataCmdArgs: b.noDataCmdArgs,
+	}
+}
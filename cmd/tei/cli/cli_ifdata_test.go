@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ifDataCli_Run(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\ninput data",
+		}, {
+			name: "no data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "no data alternative",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}).
+				NoDataCmdArgs([]string{testStandbyCmd(), "alt"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd: alt\n",
+		}, {
+			name: "data alternative",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}).
+				NoDataCmdArgs([]string{testStandbyCmd(), "alt"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\ninput data",
+		}, {
+			name: "no data alternative stdin",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}).
+				NoDataCmdArgs([]string{testStandbyCmdCat(), "alt"}).
+				CmdStdin(CmdStdinSniffed),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: alt\n\n",
+		}, {
+			name: "no data only",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				NoDataCmdArgs([]string{testStandbyCmd(), "alt"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "exit code",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				IfDataCmdArgs([]string{"/bin/sh", "-c", "cat; exit 3"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "input data",
+			wantExitCode: 3,
+		}, {
+			name: "command not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				IfDataCmdArgs([]string{"./not_exist"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ifDataCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("ifDataCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "ifDataCli.Run() outStream")
+		})
+	}
+}
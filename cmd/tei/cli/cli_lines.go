@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// linesCli replaces the empty(or whitespace-only) lines with the default value.
+//
+// 入力全体の切り替えは行わない(tei.Transformer で変換するのみ).
+type linesCli struct {
+	baseCli
+	linesDefault         string
+	linesDefaultEnv      string
+	linesDefaultTemplate string
+	linesNull            bool
+}
+
+// LinesCliBuilder adds a property to CliBuilder.
+type LinesCliBuilder interface {
+	// Lines sets the flag that the empty lines are replaced instead of switching.
+	Lines(bool) Builder
+	// LinesDefault sets the default value of the empty line.
+	LinesDefault(string) Builder
+	// LinesDefaultEnv sets the name of the environment variable that has the default value.
+	LinesDefaultEnv(string) Builder
+	// LinesDefaultTemplate sets the text/template that is rendered as the default value.
+	LinesDefaultTemplate(string) Builder
+	// LinesNull sets the flag that the lines are separated by NUL instead of newline.
+	LinesNull(bool) Builder
+}
+
+// LinesTemplateData is the data that is passed to the template of the default value.
+type LinesTemplateData struct {
+	// Env has the environment variables.
+	Env map[string]string
+	// Line is the line number(starts at 1).
+	Line int
+}
+
+// defaultFunc returns the function that returns the default value.
+func (c *linesCli) defaultFunc() (tei.LineDefaultFunc, error) {
+	n := 0
+	for _, s := range []string{c.linesDefault, c.linesDefaultEnv, c.linesDefaultTemplate} {
+		if s != "" {
+			n++
+		}
+	}
+	if n > 1 {
+		return nil, fmt.Errorf("linesCli.Run the default value, the variable and the template are conflicted")
+	}
+
+	switch {
+	case c.linesDefaultEnv != "":
+		// 未設定の場合は空文字列となる(envCli と同じ).
+		v := os.Getenv(c.linesDefaultEnv)
+		return func(int) (string, error) {
+			return v, nil
+		}, nil
+	case c.linesDefaultTemplate != "":
+		t, err := template.New("template").Funcs(templateFuncs).Parse(c.linesDefaultTemplate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "linesCli.Run parse template")
+		}
+		env := newTemplateData("", nil).Env
+		return func(lineNum int) (string, error) {
+			buf := &bytes.Buffer{}
+			if err := t.Execute(buf, &LinesTemplateData{Env: env, Line: lineNum}); err != nil {
+				return "", errors.Wrapf(err, "linesCli.Run execute template")
+			}
+			return buf.String(), nil
+		}, nil
+	}
+	v := c.linesDefault
+	return func(int) (string, error) {
+		return v, nil
+	}, nil
+}
+
+func (c *linesCli) Run(ctx context.Context) (exitCode int, err error) {
+	defaultFunc, err := c.defaultFunc()
+	if err != nil {
+		return 1, err
+	}
+	var separator byte = '\n'
+	if c.linesNull {
+		separator = 0
+	}
+	r := tei.NewLineBuilder().
+		DefaultFunc(defaultFunc).
+		Separator(separator).
+		Build().
+		Transform(c.inStream)
+	if _, err := io.Copy(c.outStream, r); err != nil {
+		return 1, errors.Wrapf(err, "linesCli.Run transforming the input")
+	}
+	return 0, nil
+}
+
+func newLinesCli(b *builder) *linesCli {
+	return &linesCli{
+		baseCli:              *newBaseCli(b),
+		linesDefault:         b.linesDefault,
+		linesDefaultEnv:      b.linesDefaultEnv,
+		linesDefaultTemplate: b.linesDefaultTemplate,
+		linesNull:            b.linesNull,
+	}
+}
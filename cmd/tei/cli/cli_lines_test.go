@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_linesCli_Run(t *testing.T) {
+	os.Setenv("TEI_TEST_LINES_DEFAULT", "(env)")
+	defer os.Unsetenv("TEI_TEST_LINES_DEFAULT")
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\n \nb\n")).
+				Lines(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\n\n\nb\n",
+		}, {
+			name: "default",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\n \nb\n")).
+				Lines(true).
+				LinesDefault("-"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\n-\n-\nb\n",
+		}, {
+			name: "env",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n")).
+				Lines(true).
+				LinesDefaultEnv("TEI_TEST_LINES_DEFAULT"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\n(env)\nb\n",
+		}, {
+			name: "env unset",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n")).
+				Lines(true).
+				LinesDefaultEnv("TEI_TEST_LINES_UNSET"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\n\nb\n",
+		}, {
+			name: "template",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n\n")).
+				Lines(true).
+				LinesDefaultTemplate(`{{.Env.TEI_TEST_LINES_DEFAULT}}{{.Line}}`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\n(env)2\nb\n(env)4\n",
+		}, {
+			name: "null",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\x00\x00b\n\x00")).
+				Lines(true).
+				LinesDefault("-").
+				LinesNull(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "a\x00-\x00b\n\x00",
+		}, {
+			name: "conflicted",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n")).
+				Lines(true).
+				LinesDefault("-").
+				LinesDefaultEnv("TEI_TEST_LINES_DEFAULT"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "template parse error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n")).
+				Lines(true).
+				LinesDefaultTemplate(`{{.Line`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "template execute error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("a\n\nb\n")).
+				Lines(true).
+				LinesDefaultTemplate(`{{.Test}}`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "a\n",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(errStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("linesCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("linesCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "linesCli.Run() outStream")
+			assert.Equal(t, "", errStream.String(), "linesCli.Run() errStream")
+		})
+	}
+}
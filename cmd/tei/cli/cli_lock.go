@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+const (
+	// LockWait is that the lock is waited for(until LockTimeout if it is set).
+	LockWait = "wait"
+	// LockNoWait is that it fails immediately if the lock is held by another one.
+	LockNoWait = "no-wait"
+)
+
+// ExitCodeLocked is the exit code of Cli.Run if the lock is not acquired.
+const ExitCodeLocked = 4
+
+// LockCliBuilder adds a property to CliBuilder.
+//
+// lock は全ての Cli で利用できる(入力の切り替えは baseCli.switchInput で行う).
+type LockCliBuilder interface {
+	// Lock sets the file that is locked exclusively while the standby source is running.
+	Lock(string) Builder
+	// LockMode sets how to wait for the lock(LockWait, LockNoWait).
+	LockMode(string) Builder
+	// LockTimeout sets the timeout to wait for the lock(0 is no timeout).
+	LockTimeout(time.Duration) Builder
+}
+
+// lockPollInterval is the interval to retry to acquire the lock.
+var lockPollInterval = 50 * time.Millisecond
+
+// acquireLock locks the file exclusively, the lock is released by closing the returned file.
+func acquireLock(ctx context.Context, name string, mode string, timeout time.Duration) (*os.File, error) {
+	switch mode {
+	case "", LockWait, LockNoWait:
+	default:
+		return nil, fmt.Errorf("acquireLock invalid mode: %s", mode)
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return nil, errors.Wrapf(err, "acquireLock make dir")
+	}
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "acquireLock open")
+	}
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+	for {
+		ok, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "acquireLock lock %s", name)
+		}
+		if ok {
+			return file, nil
+		}
+		if mode == LockNoWait {
+			file.Close()
+			return nil, &exitCodeError{
+				exitCode: ExitCodeLocked,
+				err:      fmt.Errorf("acquireLock the lock is held by another one: %s", name),
+			}
+		}
+		select {
+		case <-time.After(lockPollInterval):
+		case <-timeoutCh:
+			file.Close()
+			return nil, &exitCodeError{
+				exitCode: ExitCodeLocked,
+				err:      fmt.Errorf("acquireLock timeout(%s): %s", timeout, name),
+			}
+		case <-ctx.Done():
+			file.Close()
+			return nil, errors.Wrapf(ctx.Err(), "acquireLock lock %s", name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_acquireLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lock := filepath.Join(dir, "sub", "test.lock")
+
+	type args struct {
+		ctx     context.Context
+		mode    string
+		timeout time.Duration
+	}
+	tests := []struct {
+		name         string
+		held         bool
+		releaseAfter time.Duration
+		args         args
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			args: args{
+				ctx: context.Background(),
+			},
+		}, {
+			name: "no-wait",
+			held: true,
+			args: args{
+				ctx:  context.Background(),
+				mode: LockNoWait,
+			},
+			wantExitCode: ExitCodeLocked,
+			wantErr:      true,
+		}, {
+			name: "timeout",
+			held: true,
+			args: args{
+				ctx:     context.Background(),
+				mode:    LockWait,
+				timeout: 100 * time.Millisecond,
+			},
+			wantExitCode: ExitCodeLocked,
+			wantErr:      true,
+		}, {
+			name:         "wait",
+			held:         true,
+			releaseAfter: 100 * time.Millisecond,
+			args: args{
+				ctx:     context.Background(),
+				mode:    LockWait,
+				timeout: 5 * time.Second,
+			},
+		}, {
+			name: "canceled",
+			held: true,
+			args: args{
+				ctx: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					cancel()
+					return ctx
+				}(),
+			},
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "invalid mode",
+			args: args{
+				ctx:  context.Background(),
+				mode: "test",
+			},
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.held {
+				held, err := acquireLock(context.Background(), lock, LockNoWait, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				released := make(chan struct{})
+				defer func() { <-released }()
+				if tt.releaseAfter > 0 {
+					go func() {
+						defer close(released)
+						time.Sleep(tt.releaseAfter)
+						held.Close()
+					}()
+				} else {
+					defer func() {
+						held.Close()
+						close(released)
+					}()
+				}
+			}
+			got, err := acquireLock(tt.args.ctx, lock, tt.args.mode, tt.args.timeout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("acquireLock() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				assert.Equal(t, tt.wantExitCode, errExitCode(err), "acquireLock() exit code")
+				return
+			}
+			got.Close()
+		})
+	}
+}
+
+func Test_baseCli_switchInput_lock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lock := filepath.Join(dir, "test.lock")
+
+	held, err := acquireLock(context.Background(), lock, LockNoWait, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				String("string data").
+				Lock(lock).
+				LockMode(LockNoWait),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "no-wait",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Lock(lock).
+				LockMode(LockNoWait),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: ExitCodeLocked,
+			wantErr:      true,
+		}, {
+			name: "timeout",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				String("string data").
+				Lock(lock).
+				LockTimeout(100 * time.Millisecond),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: ExitCodeLocked,
+			wantErr:      true,
+		}, {
+			name: "ifdata",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				NoDataCmdArgs([]string{testStandbyCmd(), "test"}).
+				Lock(lock).
+				LockMode(LockNoWait),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: ExitCodeLocked,
+			wantErr:      true,
+		}, {
+			name: "other lock",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Lock(lock + ".other").
+				LockMode(LockNoWait),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "string data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("baseCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("baseCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "baseCli.Run() outStream")
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"io"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// ndjsonCli applies the defaults per record of NDJSON.
+//
+// 入力全体の切り替えは行わない(tei.Transformer で変換するのみ).
+type ndjsonCli struct {
+	baseCli
+	ndjsonDefault  string
+	ndjsonTemplate string
+}
+
+// NDJSONCliBuilder adds a property to CliBuilder.
+type NDJSONCliBuilder interface {
+	// NDJSON sets the flag that the defaults are applied per record of NDJSON instead of switching.
+	NDJSON(bool) Builder
+	// NDJSONDefault sets the record that replaces the blank line and the null record.
+	NDJSONDefault(string) Builder
+	// NDJSONTemplate sets the object that fills the missing or empty fields of the object record.
+	NDJSONTemplate(string) Builder
+}
+
+func (c *ndjsonCli) Run(ctx context.Context) (exitCode int, err error) {
+	r := tei.NewNDJSONBuilder().
+		DefaultRecord(c.ndjsonDefault).
+		Template(c.ndjsonTemplate).
+		Build().
+		Transform(c.inStream)
+	if _, err := io.Copy(c.outStream, r); err != nil {
+		return 1, errors.Wrapf(err, "ndjsonCli.Run transforming the input")
+	}
+	return 0, nil
+}
+
+func newNDJSONCli(b *builder) *ndjsonCli {
+	return &ndjsonCli{
+		baseCli:        *newBaseCli(b),
+		ndjsonDefault:  b.ndjsonDefault,
+		ndjsonTemplate: b.ndjsonTemplate,
+	}
+}
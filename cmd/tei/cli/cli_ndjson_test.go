@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ndjsonCli_Run(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("{\"a\": 1}\n\n")).
+				NDJSON(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "{\"a\": 1}\n\n",
+		}, {
+			name: "default and template",
+			builder: NewBuilder().
+				InStream(strings.NewReader("{\"a\": 1}\n\nnull\n{\"a\": 2, \"b\": \"\"}\n")).
+				NDJSON(true).
+				NDJSONDefault(`{}`).
+				NDJSONTemplate(`{"a": 0, "b": "none"}`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "{\"a\":1,\"b\":\"none\"}\n{\"a\":0,\"b\":\"none\"}\n{\"a\":0,\"b\":\"none\"}\n{\"a\":2,\"b\":\"none\"}\n",
+		}, {
+			name: "no data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				NDJSON(true).
+				NDJSONDefault(`{}`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("{\"a\": 1}\ninput data\n")).
+				NDJSON(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "{\"a\": 1}\n",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(errStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ndjsonCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("ndjsonCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "ndjsonCli.Run() outStream")
+			assert.Equal(t, "", errStream.String(), "ndjsonCli.Run() errStream")
+		})
+	}
+}
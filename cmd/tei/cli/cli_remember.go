@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// RememberCliBuilder adds a property to CliBuilder.
+//
+// remember は全ての Cli で利用できる(入力の切り替えは baseCli.switchInput で行う).
+type RememberCliBuilder interface {
+	// Remember sets the key that the non-empty input is saved with,
+	// the saved input is replayed instead of the standby source if no data from the input.
+	Remember(string) Builder
+	// RememberDir sets the directory that the input is saved in(default is "$XDG_CACHE_HOME/tei/remember").
+	RememberDir(string) Builder
+	// RememberMaxSize sets the max size of the input that is saved(0 is unlimited).
+	RememberMaxSize(int64) Builder
+	// RememberTTL sets the duration that the saved input is replayed(0 is no expiration).
+	RememberTTL(time.Duration) Builder
+}
+
+var validRememberKey = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// rememberStore saves the last non-empty input by the key.
+type rememberStore struct {
+	dir     string
+	key     string
+	maxSize int64
+	ttl     time.Duration
+}
+
+func newRememberStore(dir string, key string, maxSize int64, ttl time.Duration) (*rememberStore, error) {
+	if validRememberKey.MatchString(key) == false {
+		return nil, fmt.Errorf("rememberStore invalid key: %s", key)
+	}
+	dir, err := cacheDir(dir, "remember")
+	if err != nil {
+		return nil, errors.Wrapf(err, "rememberStore cache dir")
+	}
+	return &rememberStore{
+		dir:     dir,
+		key:     key,
+		maxSize: maxSize,
+		ttl:     ttl,
+	}, nil
+}
+
+func (s *rememberStore) name() string {
+	return filepath.Join(s.dir, s.key)
+}
+
+// load opens the saved input, returns nil if not saved or expired.
+func (s *rememberStore) load() (*os.File, error) {
+	file, err := os.Open(s.name())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "rememberStore.load open")
+	}
+	if s.ttl > 0 {
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, errors.Wrapf(err, "rememberStore.load stat")
+		}
+		if time.Since(stat.ModTime()) > s.ttl {
+			file.Close()
+			return nil, nil
+		}
+	}
+	return file, nil
+}
+
+// sizeLimitedWriter fails if the size of written bytes exceeds the limit.
+type sizeLimitedWriter struct {
+	w       io.Writer
+	limit   int64
+	written int64
+}
+
+func (l *sizeLimitedWriter) Write(p []byte) (int, error) {
+	l.written += int64(len(p))
+	if l.limit > 0 && l.written > l.limit {
+		return 0, fmt.Errorf("rememberStore the input exceeds max size(%d)", l.limit)
+	}
+	return l.w.Write(p)
+}
+
+// writer returns the writer of the temporary file and the function to commit it.
+//
+// commit されるまでは前回の入力は置き換えない(rename で置き換える).
+func (s *rememberStore) writer() (w io.Writer, commit func() error, abort func(), err error) {
+	tmp, commit, abort, err := cacheWriter(s.dir, s.name())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &sizeLimitedWriter{w: tmp, limit: s.maxSize}, commit, abort, nil
+}
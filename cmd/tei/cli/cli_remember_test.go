@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_baseCli_switchInput_remember(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	type args struct {
+		ctx context.Context
+	}
+	// テストケースは順番に実行され、前のケースで保存された入力を利用する.
+	tests := []struct {
+		name         string
+		builder      Builder
+		before       func()
+		args         args
+		want         string
+		wantSaved    string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "save",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				String("string data").
+				Remember("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data",
+			wantSaved: "input data",
+		}, {
+			name: "replay",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Remember("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data",
+			wantSaved: "input data",
+		}, {
+			name: "replay newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				String("string data").
+				Remember("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data",
+			wantSaved: "input data",
+		}, {
+			name: "replay stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				String("string data").
+				Remember("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data",
+			wantSaved: "input data",
+		}, {
+			name: "not saved",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Remember("test_other"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "string data",
+		}, {
+			name: "not saved stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				String("string data").
+				Remember("test_other"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "string data",
+		}, {
+			name: "exceeds max size",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data 2")).
+				String("string data").
+				Remember("test").
+				RememberMaxSize(5),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data 2",
+			wantSaved: "input data",
+		}, {
+			name: "max size",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data 2")).
+				String("string data").
+				Remember("test").
+				RememberMaxSize(12),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data 2",
+			wantSaved: "input data 2",
+		}, {
+			name: "ttl",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Remember("test").
+				RememberTTL(time.Hour),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "input data 2",
+			wantSaved: "input data 2",
+		}, {
+			name: "expired",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("string data").
+				Remember("test").
+				RememberTTL(time.Hour),
+			before: func() {
+				old := time.Now().Add(-2 * time.Hour)
+				os.Chtimes(filepath.Join(dir, "test"), old, old)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "string data",
+			wantSaved: "input data 2",
+		}, {
+			name: "ifdata",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				IfDataCmdArgs([]string{testStandbyCmdCat(), "test"}).
+				Remember("test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:      "standby cmd cat: test\ninput data 2",
+			wantSaved: "input data 2",
+		}, {
+			name: "invalid key",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				String("string data").
+				Remember("../test"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				RememberDir(dir).
+				OutStream(outStream).
+				ErrStream(ioutil.Discard).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("baseCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("baseCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "baseCli.Run() outStream")
+			if tt.wantSaved != "" {
+				saved, err := ioutil.ReadFile(filepath.Join(dir, "test"))
+				assert.Nil(t, err, "saved input")
+				assert.Equal(t, tt.wantSaved, string(saved), "saved input")
+			}
+		})
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	assert.Nil(t, err, "temporary files")
+	assert.Empty(t, files, "temporary files")
+}
@@ -1,32 +1,294 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/internal/errors"
 )
 
 type runCli struct {
 	baseCli
-	cmdArgs []string
+	cmdArgs  []string
+	chdir    string
+	env      []string
+	clearEnv bool
+	envFile  string
+	exec     bool
+	cmdStdin string
+
+	cache      bool
+	cacheTTL   time.Duration
+	cacheStale time.Duration
+	cacheEnv   []string
+	cacheDir   string
 }
 
+const (
+	// CmdStdinNone is that nothing is connected to stdin of the command.
+	CmdStdinNone = "none"
+	// CmdStdinSniffed is that the input that is switched is connected to stdin of the command(the terminal is not read).
+	CmdStdinSniffed = "sniffed"
+	// CmdStdinInput is that the sniffed bytes and the rest of the input are connected to stdin of the command.
+	CmdStdinInput = "input"
+)
+
 // RunCliBuilder adds a property to CliBuilder.
 type RunCliBuilder interface {
 	CmdArgs([]string) Builder
+	// Chdir sets the working directory of the command.
+	Chdir(string) Builder
+	// Env sets the environment variables("KEY=VAL") that are added to the command.
+	Env([]string) Builder
+	// ClearEnv sets the flag that the command does not inherit the environment.
+	ClearEnv(bool) Builder
+	// EnvFile sets the file that has the environment variables("KEY=VAL" per line).
+	EnvFile(string) Builder
+	// Exec sets the flag that replaces the current process with the command instead of copying its output.
+	Exec(bool) Builder
+	// CmdStdin sets what is connected to stdin of the command(CmdStdinNone, CmdStdinSniffed, CmdStdinInput).
+	CmdStdin(string) Builder
+	// Cache sets the flag that the output of the command is cached.
+	Cache(bool) Builder
+	// CacheTTL sets the duration that the cached output is used(0 is no expiration).
+	CacheTTL(time.Duration) Builder
+	// CacheStale sets the duration after TTL that the stale output is used while refreshing it in the background.
+	CacheStale(time.Duration) Builder
+	// CacheEnv sets the names of the environment variables that are added to the cache key.
+	CacheEnv([]string) Builder
+	// CacheDir sets the directory that the output is cached in(default is "$XDG_CACHE_HOME/tei/run").
+	CacheDir(string) Builder
+}
+
+// execFunc replaces the current process with the command.
+//
+// テストでは差し替える(実際に exec するとテストのプロセスが置き換わる).
+var execFunc = func(dir string, args []string, env []string) error {
+	if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return err
+		}
+	}
+	cmdPath, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(cmdPath, args, env)
+}
+
+// readEnvFile reads the environment variables from the file.
+//
+// 空行と "#" で始まる行は無視する.
+func readEnvFile(name string) ([]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "readEnvFile open file")
+	}
+	defer file.Close()
+
+	env := []string{}
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("readEnvFile invalid line %s:%d: %s", name, lineNo, line)
+		}
+		v := kv[1]
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		env = append(env, kv[0]+"="+v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "readEnvFile scan file")
+	}
+	return env, nil
+}
+
+// cmdEnv returns the environment of the command.
+//
+// 後から追加した値が優先される(os/exec で重複は除去される).
+func (c *runCli) cmdEnv(reason tei.Reason, sniffed []byte) ([]string, error) {
+	env := []string{}
+	if c.clearEnv == false {
+		env = append(env, os.Environ()...)
+	}
+	if c.envFile != "" {
+		e, err := readEnvFile(c.envFile)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, e...)
+	}
+	for _, e := range c.env {
+		if kv := strings.SplitN(e, "=", 2); len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("runCli invalid env: %s", e)
+		}
+		env = append(env, e)
+	}
+	if reason != "" {
+		env = append(env,
+			"TEI_REASON="+string(reason),
+			"TEI_SNIFFED_BYTES="+strconv.Itoa(len(sniffed)),
+		)
+	}
+	return env, nil
+}
+
+// stdin returns the reader that is connected to stdin of the command.
+func (c *runCli) stdin(reason tei.Reason, sniffed []byte) (io.Reader, error) {
+	switch c.cmdStdin {
+	case "", CmdStdinNone:
+		return nil, nil
+	case CmdStdinSniffed:
+		// --json-path の場合は sniffed が入力の先頭部分のみとなるので、残りも続ける.
+		return switchedInput(reason, sniffed, c.inStream), nil
+	case CmdStdinInput:
+		if _, ok := c.inStream.(*os.File); ok && len(sniffed) == 0 {
+			return c.inStream, nil
+		}
+		return io.MultiReader(bytes.NewReader(sniffed), c.inStream), nil
+	}
+	return nil, fmt.Errorf("runCli invalid stdin: %s", c.cmdStdin)
+}
+
+// command returns exec.Cmd that is configured by the properties of runCli.
+//
+// reason が空の場合(切り替えが発生していない場合)は TEI_REASON 等は設定されない.
+func (c *runCli) command(ctx context.Context, args []string, reason tei.Reason, sniffed []byte) (*exec.Cmd, error) {
+	env, err := c.cmdEnv(reason, sniffed)
+	if err != nil {
+		return nil, err
+	}
+	cmdPath := args[0]
+	var cmdArgs []string
+	if len(args) > 1 {
+		cmdArgs = args[1:]
+	} else {
+		cmdArgs = []string{}
+	}
+	cmd := exec.CommandContext(ctx, cmdPath, cmdArgs...)
+	cmd.Dir = c.chdir
+	cmd.Env = env
+	return cmd, nil
+}
+
+// cached returns the cached output, returns nil if not cached.
+//
+// stale の場合はバックグラウンドで更新する(wait は Run の最後で呼び出す).
+func (c *runCli) cached(cache *runCache, reason tei.Reason, sniffed []byte) (file *os.File, wait func(), err error) {
+	file, fresh, err := cache.load()
+	if err != nil || file == nil {
+		return nil, nil, err
+	}
+	if fresh {
+		return file, func() {}, nil
+	}
+	wait, err = c.refresh(cache, reason, sniffed)
+	if err != nil {
+		fmt.Fprintf(c.errStream, "Warning in runCli: the cache is not refreshed: %s\n", err)
+		return file, func() {}, nil
+	}
+	return file, wait, nil
 }
 
 func (c *runCli) Run(ctx context.Context) (exitCode int, err error) {
+	switch c.cmdStdin {
+	case "", CmdStdinNone, CmdStdinSniffed, CmdStdinInput:
+	default:
+		// 入力にデータがある場合は stdin を使わないので、実行前に確認する.
+		return 1, fmt.Errorf("runCli.Run invalid stdin: %s", c.cmdStdin)
+	}
+	if c.exec && c.cache {
+		return 1, fmt.Errorf("runCli.Run exec and cache can not be used together")
+	}
+	if c.exec && c.lock != "" {
+		// ロックのファイルは close-on-exec なので、exec したコマンドの実行中は保持されない.
+		return 1, fmt.Errorf("runCli.Run exec and lock can not be used together")
+	}
+	if c.exec && c.cmdStdin != "" && c.cmdStdin != CmdStdinNone {
+		// exec した場合、コマンドは tei の stdin をそのまま引き継ぐ.
+		return 1, fmt.Errorf("runCli.Run exec can not be used with stdin: %s", c.cmdStdin)
+	}
+	if c.cache && c.cmdStdin != "" && c.cmdStdin != CmdStdinNone {
+		// キャッシュのキーには stdin の内容が含まれていない.
+		return 1, fmt.Errorf("runCli.Run cache can not be used with stdin: %s", c.cmdStdin)
+	}
+	var reason tei.Reason
+	var sniffed []byte
+	var cachedFile *os.File
+	waitRefresh := func() {}
+	defer func() {
+		if cachedFile != nil {
+			cachedFile.Close()
+		}
+		waitRefresh()
+	}()
+	c.teiBuilder = c.teiBuilder.OnSwitch(func(r tei.Reason, b []byte) {
+		reason = r
+		sniffed = b
+	})
 	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		if c.exec {
+			// 成功した場合は戻ってこない.
+			env, err := c.cmdEnv(reason, sniffed)
+			if err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "runCli run - command args(%s)", c.cmdArgs))
+			}
+			err = execFunc(c.chdir, c.cmdArgs, env)
+			return tei.ErrReader(errors.Wrapf(err, "runCli run - exec args(%s)", c.cmdArgs))
+		}
+		var cache *runCache
+		if c.cache {
+			env, err := c.cmdEnv(reason, sniffed)
+			if err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "runCli run - command args(%s)", c.cmdArgs))
+			}
+			cache, err = c.newRunCache(env)
+			if err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "runCli run - cache args(%s)", c.cmdArgs))
+			}
+			file, wait, err := c.cached(cache, reason, sniffed)
+			if err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "runCli run - cache args(%s)", c.cmdArgs))
+			}
+			if file != nil {
+				cachedFile = file
+				waitRefresh = wait
+				return file
+			}
+		}
 		r, w := io.Pipe()
 		go func(w *io.PipeWriter) {
 			var cmdErr error
 			errStream := &strings.Builder{}
+			var cacheCommit func() error
+			var cacheAbort func()
 			defer func() {
+				if cacheCommit != nil {
+					// 成功した場合のみキャッシュする.
+					if cmdErr == nil && errStream.Len() == 0 {
+						if err := cacheCommit(); err != nil {
+							cmdErr = errors.Wrapf(err, "runCli run - cache args(%s)", c.cmdArgs)
+						}
+					} else {
+						cacheAbort()
+					}
+				}
 				switch {
 				case cmdErr != nil:
 					w.CloseWithError(cmdErr)
@@ -37,15 +299,28 @@ func (c *runCli) Run(ctx context.Context) (exitCode int, err error) {
 				}
 				w.Close()
 			}()
-			cmdPath := c.cmdArgs[0]
-			var cmdArgs []string
-			if len(c.cmdArgs) > 1 {
-				cmdArgs = c.cmdArgs[1:]
-			} else {
-				cmdArgs = []string{}
+			cmd, err := c.command(ctx, c.cmdArgs, reason, sniffed)
+			if err != nil {
+				cmdErr = errors.Wrapf(err, "runCli run - command args(%s)", c.cmdArgs)
+				return
 			}
-			cmd := exec.CommandContext(ctx, cmdPath, cmdArgs...)
+			stdin, err := c.stdin(reason, sniffed)
+			if err != nil {
+				cmdErr = errors.Wrapf(err, "runCli run - stdin args(%s)", c.cmdArgs)
+				return
+			}
+			cmd.Stdin = stdin
 			cmd.Stdout = w
+			if cache != nil {
+				tmp, commit, abort, err := cache.writer()
+				if err != nil {
+					cmdErr = errors.Wrapf(err, "runCli run - cache args(%s)", c.cmdArgs)
+					return
+				}
+				cacheCommit = commit
+				cacheAbort = abort
+				cmd.Stdout = io.MultiWriter(w, tmp)
+			}
 			cmd.Stderr = errStream
 			if err := cmd.Start(); err != nil {
 				cmdErr = errors.Wrapf(err, "runCli run - start args(%s)", c.cmdArgs)
@@ -63,7 +338,19 @@ func (c *runCli) Run(ctx context.Context) (exitCode int, err error) {
 
 func newRunCli(b *builder) *runCli {
 	return &runCli{
-		baseCli: *newBaseCli(b),
-		cmdArgs: b.cmdArgs,
+		baseCli:  *newBaseCli(b),
+		cmdArgs:  b.cmdArgs,
+		chdir:    b.chdir,
+		env:      b.env,
+		clearEnv: b.clearEnv,
+		envFile:  b.envFile,
+		exec:     b.exec,
+		cmdStdin: b.cmdStdin,
+
+		cache:      b.cache,
+		cacheTTL:   b.cacheTTL,
+		cacheStale: b.cacheStale,
+		cacheEnv:   b.cacheEnv,
+		cacheDir:   b.cacheDir,
 	}
 }
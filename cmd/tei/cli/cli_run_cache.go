@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// runCache stores the output of the standby command.
+//
+// ファイル名は argv, cwd と選択された環境変数から決める.
+type runCache struct {
+	dir   string
+	name  string
+	ttl   time.Duration
+	stale time.Duration
+}
+
+// runCacheKey is the source of the name of runCache.
+type runCacheKey struct {
+	Args []string `json:"args"`
+	Dir  string   `json:"dir"`
+	Env  []string `json:"env"`
+}
+
+// newRunCache returns runCache for the command that runs with env.
+func (c *runCli) newRunCache(env []string) (*runCache, error) {
+	dir, err := cacheDir(c.cacheDir, "run")
+	if err != nil {
+		return nil, errors.Wrapf(err, "runCache cache dir")
+	}
+	// chdir してからコマンドを実行するので絶対パスにしておく.
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "runCache cache dir")
+	}
+
+	cwd := c.chdir
+	if filepath.IsAbs(cwd) == false {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, errors.Wrapf(err, "runCache working dir")
+		}
+		cwd = filepath.Join(wd, cwd)
+	}
+	key := runCacheKey{
+		Args: c.cmdArgs,
+		Dir:  cwd,
+		Env:  []string{},
+	}
+	for _, name := range c.cacheEnv {
+		// 後から追加した値が優先される(cmdEnv と同じ).
+		kv := name
+		for _, e := range env {
+			if strings.HasPrefix(e, name+"=") {
+				kv = e
+			}
+		}
+		key.Env = append(key.Env, kv)
+	}
+	b, err := json.Marshal(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "runCache marshal key")
+	}
+	sum := sha256.Sum256(b)
+	return &runCache{
+		dir:   dir,
+		name:  filepath.Join(dir, hex.EncodeToString(sum[:])),
+		ttl:   c.cacheTTL,
+		stale: c.cacheStale,
+	}, nil
+}
+
+// load opens the cached output.
+//
+// 期限切れ(stale を含む)の場合は nil を返す.
+// fresh が false の場合は stale なので更新が必要.
+func (r *runCache) load() (file *os.File, fresh bool, err error) {
+	file, err = os.Open(r.name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, errors.Wrapf(err, "runCache.load open")
+	}
+	if r.ttl <= 0 {
+		return file, true, nil
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, false, errors.Wrapf(err, "runCache.load stat")
+	}
+	age := time.Since(stat.ModTime())
+	switch {
+	case age <= r.ttl:
+		return file, true, nil
+	case age <= r.ttl+r.stale:
+		return file, false, nil
+	}
+	file.Close()
+	return nil, false, nil
+}
+
+// writer returns the temporary file and the function to commit it.
+func (r *runCache) writer() (w *os.File, commit func() error, abort func(), err error) {
+	return cacheWriter(r.dir, r.name)
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_runCli_Run_cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cached は キャッシュされているファイルを返す(一時ファイルは除く).
+	cached := func() []string {
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret := []string{}
+		for _, name := range files {
+			if strings.HasPrefix(filepath.Base(name), ".") == false {
+				ret = append(ret, name)
+			}
+		}
+		return ret
+	}
+	setAge := func(age time.Duration) {
+		for _, name := range cached() {
+			mtime := time.Now().Add(-age)
+			os.Chtimes(name, mtime, mtime)
+		}
+	}
+	want := func(v string) string {
+		return "standby cmd env: " + cwd + " no-data 0 " + v + "  " + os.Getenv("HOME") + "\n"
+	}
+
+	type args struct {
+		ctx context.Context
+	}
+	// テストケースは順番に実行され、前のケースでキャッシュされた出力を利用する.
+	tests := []struct {
+		name         string
+		builder      Builder
+		before       func()
+		args         args
+		want         string
+		wantCached   int
+		wantErrOut   string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v1"}).
+				Cache(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       "input data",
+			wantCached: 0,
+		}, {
+			name: "miss",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v1"}).
+				Cache(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       want("v1"),
+			wantCached: 1,
+		}, {
+			name: "hit",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v2"}).
+				Cache(true).
+				CacheTTL(time.Hour),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       want("v1"),
+			wantCached: 1,
+		}, {
+			name: "env key",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v2"}).
+				Cache(true).
+				CacheEnv([]string{"TEST_VAR1"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       want("v2"),
+			wantCached: 2,
+		}, {
+			name: "expired",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v3"}).
+				Cache(true).
+				CacheTTL(time.Hour).
+				CacheStale(time.Hour),
+			before: func() {
+				setAge(3 * time.Hour)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       want("v3"),
+			wantCached: 2,
+		}, {
+			name: "stale",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1=v4"}).
+				Cache(true).
+				CacheTTL(time.Hour).
+				CacheStale(time.Hour),
+			before: func() {
+				setAge(90 * time.Minute)
+			},
+			args: args{
+				ctx: context.Background(),
+			},
+			want:       want("v3"),
+			wantCached: 2,
+		}, {
+			name: "error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdErrOut(), "test"}).
+				Cache(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantCached:   2,
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "exec",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Cache(true).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantCached:   2,
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Cache(true).
+				CmdStdin(CmdStdinInput),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantCached:   2,
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				CacheDir(dir).
+				OutStream(outStream).
+				ErrStream(ioutil.Discard).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("runCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "runCli.Run() outStream")
+			assert.Equal(t, tt.wantCached, len(cached()), "runCli.Run() cached")
+		})
+	}
+
+	// stale の場合はバックグラウンドで更新される.
+	refreshed := false
+	for i := 0; i < 50 && refreshed == false; i++ {
+		for _, name := range cached() {
+			b, _ := ioutil.ReadFile(name)
+			if string(b) == want("v4") {
+				refreshed = true
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.True(t, refreshed, "runCli.Run() refreshed in the background")
+	var files []string
+	for i := 0; i < 50; i++ {
+		if files, err = filepath.Glob(filepath.Join(dir, ".tmp-*")); err != nil || len(files) == 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Nil(t, err, "temporary files")
+	assert.Empty(t, files, "temporary files")
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// runCacheRefreshScript runs the command and replaces the cache only if the command succeeded.
+//
+// 引数: $1=一時ファイル $2=キャッシュファイル $3...=コマンド.
+// stderr へ出力があった場合も失敗として扱う(runCli と同じ).
+const runCacheRefreshScript = `tmp="$1"; name="$2"; shift 2
+if "$@" > "$tmp" 2> "$tmp.err" && [ ! -s "$tmp.err" ]; then
+  mv -f "$tmp" "$name"
+fi
+rm -f "$tmp" "$tmp.err"`
+
+// refresh refreshes the cache in the background.
+//
+// tei の終了を待たせないように、プロセスを切り離して実行する.
+func (c *runCli) refresh(cache *runCache, reason tei.Reason, sniffed []byte) (wait func(), err error) {
+	tmp, err := cacheTempFile(cache.dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	args := append([]string{"/bin/sh", "-c", runCacheRefreshScript, "sh", tmp.Name(), cache.name}, c.cmdArgs...)
+	cmd, err := c.command(context.Background(), args, reason, sniffed)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		os.Remove(tmp.Name())
+		return nil, errors.Wrapf(err, "runCli.refresh start args(%s)", c.cmdArgs)
+	}
+	cmd.Process.Release()
+	return func() {}, nil
+}
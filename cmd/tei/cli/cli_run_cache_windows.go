@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package cli
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// refresh refreshes the cache in the background.
+//
+// Windows ではプロセスを切り離さずに実行し、Run の最後で終了を待つ.
+func (c *runCli) refresh(cache *runCache, reason tei.Reason, sniffed []byte) (wait func(), err error) {
+	cmd, err := c.command(context.Background(), c.cmdArgs, reason, sniffed)
+	if err != nil {
+		return nil, err
+	}
+	w, commit, abort, err := cache.writer()
+	if err != nil {
+		return nil, err
+	}
+	errStream := &strings.Builder{}
+	cmd.Stdout = w
+	cmd.Stderr = errStream
+	if err := cmd.Start(); err != nil {
+		abort()
+		return nil, errors.Wrapf(err, "runCli.refresh start args(%s)", c.cmdArgs)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := cmd.Wait(); err != nil || errStream.Len() > 0 {
+			abort()
+			return
+		}
+		commit()
+	}()
+	return func() { <-done }, nil
+}
@@ -2,10 +2,13 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/hankei6km/go-tei"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,6 +73,153 @@ func Test_runCli_Run(t *testing.T) {
 			want:         "",
 			wantExitCode: 1,
 			wantErr:      true,
+		}, {
+			name: "env",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Chdir(os.TempDir()).
+				Env([]string{"TEST_VAR1=env1", "TEST_VAR2=env2"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd env: " + os.TempDir() + " newline 1 env1 env2 " + os.Getenv("HOME") + "\n",
+		}, {
+			name: "env file",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Chdir(os.TempDir()).
+				Env([]string{"TEST_VAR1=env1"}).
+				EnvFile(testStandbyEnvFile()),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd env: " + os.TempDir() + " no-data 0 env1 file2 " + os.Getenv("HOME") + "\n",
+		}, {
+			name: "clear env",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Chdir(os.TempDir()).
+				ClearEnv(true).
+				EnvFile(testStandbyEnvFile()),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd env: " + os.TempDir() + " no-data 0 file1 file2 \n",
+		}, {
+			name: "invalid env",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Env([]string{"TEST_VAR1"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "lock",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{"foo", "test"}).
+				Lock(filepath.Join(os.TempDir(), "tei-test-exec.lock")).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "stdin none",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin(CmdStdinNone),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\n",
+		}, {
+			name: "stdin sniffed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\r\n")).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin(CmdStdinSniffed),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\n\r\n",
+		}, {
+			name: "stdin sniffed json path",
+			builder: NewBuilder().
+				TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+				InStream(strings.NewReader(`{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`)).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin(CmdStdinSniffed),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\n" + `{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`,
+		}, {
+			name: "stdin input",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin(CmdStdinInput),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\n\n",
+		}, {
+			name: "stdin input terminal",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin(CmdStdinInput),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd cat: test\n",
+		}, {
+			name: "stdin invalid",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin("invalid"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "stdin invalid with data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				CmdArgs([]string{testStandbyCmdCat(), "test"}).
+				CmdStdin("invalid"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "env file not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				EnvFile(testDummyFile() + ".not_exist"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
 		},
 	}
 	for _, tt := range tests {
@@ -90,3 +240,105 @@ func Test_runCli_Run(t *testing.T) {
 		})
 	}
 }
+
+func Test_runCli_Run_exec(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	type execArgs struct {
+		dir  string
+		args []string
+		env  []string
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExecArgs *execArgs
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				CmdArgs([]string{"foo", "test"}).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "exec",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{"foo", "test"}).
+				Chdir("/path/to").
+				ClearEnv(true).
+				Env([]string{"FOO=foo"}).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+			wantExecArgs: &execArgs{
+				dir:  "/path/to",
+				args: []string{"foo", "test"},
+				env:  []string{"FOO=foo", "TEI_REASON=newline", "TEI_SNIFFED_BYTES=1"},
+			},
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				CmdArgs([]string{"foo", "test"}).
+				CmdStdin(CmdStdinSniffed).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "stdin none",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				CmdArgs([]string{"foo", "test"}).
+				CmdStdin(CmdStdinNone).
+				Exec(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveExecFunc := execFunc
+			defer func() { execFunc = saveExecFunc }()
+			var gotExecArgs *execArgs
+			execFunc = func(dir string, args []string, env []string) error {
+				gotExecArgs = &execArgs{dir: dir, args: args, env: env}
+				return errors.New("exec test")
+			}
+
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("runCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "runCli.Run() outStream")
+			assert.Equal(t, tt.wantExecArgs, gotExecArgs, "runCli.Run() exec")
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+type socketCli struct {
+	baseCli
+	socket        string
+	socketRequest string
+}
+
+// SocketCliBuilder adds a property to CliBuilder.
+type SocketCliBuilder interface {
+	// Socket sets the path of the Unix domain socket that is used as the standby source.
+	Socket(string) Builder
+	// SocketRequest sets the line that is sent to the socket before reading.
+	SocketRequest(string) Builder
+}
+
+func (c *socketCli) Run(ctx context.Context) (exitCode int, err error) {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		var d net.Dialer
+		cn, err := d.DialContext(ctx, "unix", c.socket)
+		if err != nil {
+			return tei.ErrReader(errors.Wrapf(err, "socketCli.Run dial socket"))
+		}
+		conn = cn
+		if c.socketRequest != "" {
+			req := c.socketRequest
+			if strings.HasSuffix(req, "\n") == false {
+				req = req + "\n"
+			}
+			if _, err := io.WriteString(conn, req); err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "socketCli.Run send request"))
+			}
+			// リクエストの終わりを伝える.
+			if err := conn.(*net.UnixConn).CloseWrite(); err != nil {
+				return tei.ErrReader(errors.Wrapf(err, "socketCli.Run close write"))
+			}
+		}
+		return conn
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newSocketCli(b *builder) *socketCli {
+	return &socketCli{
+		baseCli:       *newBaseCli(b),
+		socket:        b.socket,
+		socketRequest: b.socketRequest,
+	}
+}
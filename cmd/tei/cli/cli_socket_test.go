@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSocketServer serves the standby data on the Unix domain socket.
+func testSocketServer(t *testing.T, handler func(conn net.Conn)) (socket string, closeFunc func()) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket = filepath.Join(dir, "test.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			handler(conn)
+			conn.Close()
+		}
+	}()
+	return socket, func() {
+		l.Close()
+		wg.Wait()
+		os.RemoveAll(dir)
+	}
+}
+
+func Test_socketCli_Run(t *testing.T) {
+	// リクエストを受け取ってから応答する.
+	socket, closeFunc := testSocketServer(t, func(conn net.Conn) {
+		req, _ := ioutil.ReadAll(conn)
+		conn.Write([]byte("socket data: " + string(req)))
+	})
+	defer closeFunc()
+	// 接続されたらすぐに応答する.
+	socketNoReq, closeFuncNoReq := testSocketServer(t, func(conn net.Conn) {
+		conn.Write([]byte("socket data\n"))
+	})
+	defer closeFuncNoReq()
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Socket(socket),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Socket(socket).
+				SocketRequest("get"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "socket data: get\n",
+		}, {
+			name: "no request",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Socket(socketNoReq),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "socket data\n",
+		}, {
+			name: "stdin",
+			builder: NewBuilder().
+				InStream(os.Stdin).
+				Socket(socket).
+				SocketRequest("get\n"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "socket data: get\n",
+		}, {
+			name: "not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Socket(socket + ".not_exist"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("socketCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("socketCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "socketCli.Run() outStream")
+		})
+	}
+}
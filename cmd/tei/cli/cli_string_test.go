@@ -41,6 +41,33 @@ func Test_stringCli_Run(t *testing.T) {
 				ctx: context.Background(),
 			},
 			want: "string data",
+		}, {
+			name: "nul",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String("\x00string\x00data\x00"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "\x00string\x00data\x00",
+		}, {
+			name: "empty",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				String(""),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "empty with data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				String(""),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
 		}, {
 			name: "stdin",
 			builder: NewBuilder().
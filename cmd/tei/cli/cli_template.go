@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+type templateCli struct {
+	baseCli
+	template     string
+	templateFile string
+}
+
+// TemplateCliBuilder adds a property to CliBuilder.
+type TemplateCliBuilder interface {
+	// Template sets the text/template that is rendered as the standby source.
+	Template(string) Builder
+	// TemplateFile sets the file that has the text/template.
+	TemplateFile(string) Builder
+}
+
+// TemplateData is the data that is passed to the template.
+type TemplateData struct {
+	// Env has the environment variables.
+	Env map[string]string
+	// Time is the time when the template is rendered.
+	Time time.Time
+	// Hostname is the host name reported by the kernel.
+	Hostname string
+	// Reason is the reason why the input is switched("no-data", "newline", "terminal", "empty-json", "header-only").
+	Reason string
+	// Sniffed has the input that is switched(the sniffed bytes and the rest of the input).
+	Sniffed string
+}
+
+// templateFuncs are the helper functions that can be used in the template.
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	},
+	"default": func(d interface{}, v interface{}) interface{} {
+		if v == nil {
+			return d
+		}
+		if rv := reflect.ValueOf(v); rv.IsZero() {
+			return d
+		}
+		return v
+	},
+	"now": time.Now,
+}
+
+// newTemplateData returns TemplateData that is filled by the current environment.
+func newTemplateData(reason tei.Reason, sniffed []byte) *TemplateData {
+	env := map[string]string{}
+	for _, e := range os.Environ() {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 {
+			env[kv[0]] = kv[1]
+		}
+	}
+	hostname, _ := os.Hostname()
+	return &TemplateData{
+		Env:      env,
+		Time:     time.Now(),
+		Hostname: hostname,
+		Reason:   string(reason),
+		Sniffed:  string(sniffed),
+	}
+}
+
+// parse parses the template.
+//
+// 入力にデータがある場合でもテンプレートの誤りを検出できるように Run の最初で呼ぶ.
+func (c *templateCli) parse() (*template.Template, error) {
+	text := c.template
+	name := "template"
+	if c.templateFile != "" {
+		b, err := ioutil.ReadFile(c.templateFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "templateCli.Run read template file")
+		}
+		text = string(b)
+		name = c.templateFile
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "templateCli.Run parse template")
+	}
+	return t, nil
+}
+
+func (c *templateCli) Run(ctx context.Context) (exitCode int, err error) {
+	t, err := c.parse()
+	if err != nil {
+		return 1, err
+	}
+
+	var reason tei.Reason
+	var sniffed []byte
+	c.teiBuilder = c.teiBuilder.OnSwitch(func(r tei.Reason, b []byte) {
+		reason = r
+		sniffed = b
+	})
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		// --json-path の場合は sniffed が入力の先頭部分のみとなるので、残りも読み込む.
+		input, err := ioutil.ReadAll(switchedInput(reason, sniffed, c.inStream))
+		if err != nil {
+			return tei.ErrReader(errors.Wrapf(err, "templateCli.Run reading the input"))
+		}
+		buf := &bytes.Buffer{}
+		if err := t.Execute(buf, newTemplateData(reason, input)); err != nil {
+			return tei.ErrReader(errors.Wrapf(err, "templateCli.Run execute template"))
+		}
+		return buf
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newTemplateCli(b *builder) *templateCli {
+	return &templateCli{
+		baseCli:      *newBaseCli(b),
+		template:     b.template,
+		templateFile: b.templateFile,
+	}
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_templateCli_Run(t *testing.T) {
+	saveEnv, saveOk := os.LookupEnv("TEI_TEST_TEMPLATE")
+	os.Setenv("TEI_TEST_TEMPLATE", "template data")
+	defer func() {
+		if saveOk {
+			os.Setenv("TEI_TEST_TEMPLATE", saveEnv)
+			return
+		}
+		os.Unsetenv("TEI_TEST_TEMPLATE")
+	}()
+	hostname, _ := os.Hostname()
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Template("{{ .Hostname }}"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Template("{{ .Env.TEI_TEST_TEMPLATE }} {{ .Hostname }} {{ .Reason }}\n"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "template data " + hostname + " no-data\n",
+		}, {
+			name: "empty",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Template(""),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "",
+		}, {
+			name: "sniffed",
+			builder: NewBuilder().
+				InStream(strings.NewReader("\n")).
+				Template("{{ json .Sniffed }} {{ .Reason }}"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: `"\n" newline`,
+		}, {
+			name: "sniffed json path",
+			builder: NewBuilder().
+				TeiBuilder(tei.NewBuilder().JSON(true).JSONPath(".items")).
+				InStream(strings.NewReader(`{"items":[],"next":"` + strings.Repeat("x", 2000) + `"}`)).
+				Template("{{ len .Sniffed }} {{ .Reason }}"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "2022 empty-json",
+		}, {
+			name: "funcs",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Template(`{{ default "default data" .Env.TEI_TEST_TEMPLATE_UNSET }} {{ json .Env.TEI_TEST_TEMPLATE }} {{ if gt now.Year 2000 }}now{{ end }} {{ if .Time.IsZero }}zero{{ else }}time{{ end }}`),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: `default data "template data" now time`,
+		}, {
+			name: "file",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				TemplateFile(testStandbyTemplateFile()),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "template data no-data\n",
+		}, {
+			name: "file not exist",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				TemplateFile(testStandbyTemplateFile() + ".not_exist"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "parse error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				Template("{{ .Hostname "),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "execute error",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				Template("{{ .NotExist }}"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("templateCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("templateCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "templateCli.Run() outStream")
+		})
+	}
+}
@@ -24,10 +24,54 @@ func Test_builder_Build(t *testing.T) {
 			name:    "run",
 			builder: NewBuilder().CmdArgs([]string{"test"}),
 			want:    &runCli{},
+		}, {
+			name:    "try",
+			builder: NewBuilder().TryCmdArgs([]string{"test"}).CmdArgs([]string{"test"}),
+			want:    &tryCli{},
+		}, {
+			name:    "ifdata",
+			builder: NewBuilder().IfDataCmdArgs([]string{"test"}),
+			want:    &ifDataCli{},
+		}, {
+			name:    "nodata",
+			builder: NewBuilder().NoDataCmdArgs([]string{"test"}),
+			want:    &ifDataCli{},
 		}, {
 			name:    "string",
 			builder: NewBuilder().String("test"),
 			want:    &stringCli{},
+		}, {
+			name:    "env",
+			builder: NewBuilder().EnvVar("TEST"),
+			want:    &envCli{},
+		}, {
+			name:    "fd",
+			builder: NewBuilder().Fd(3),
+			want:    &fdCli{},
+		}, {
+			name:    "check",
+			builder: NewBuilder().Check(true).String("test"),
+			want:    &checkCli{},
+		}, {
+			name:    "socket",
+			builder: NewBuilder().Socket("test.sock"),
+			want:    &socketCli{},
+		}, {
+			name:    "fifo",
+			builder: NewBuilder().Fifo("test.fifo"),
+			want:    &fifoCli{},
+		}, {
+			name:    "url",
+			builder: NewBuilder().URL("http://localhost/"),
+			want:    &urlCli{},
+		}, {
+			name:    "template",
+			builder: NewBuilder().Template("{{ .Hostname }}"),
+			want:    &templateCli{},
+		}, {
+			name:    "template file",
+			builder: NewBuilder().TemplateFile("test.tmpl"),
+			want:    &templateCli{},
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// tryCli runs the primary command and switches its output to the fallback source
+// if no data from the primary command.
+//
+// fallback は tryCmdArgs 以外のプロパティ(CmdArgs, File, String 等)から組み立てる.
+type tryCli struct {
+	runCli
+	tryCmdArgs      []string
+	fallbackBuilder builder
+}
+
+// TryCliBuilder adds a property to CliBuilder.
+type TryCliBuilder interface {
+	// TryCmdArgs sets the primary command that is run instead of reading the input.
+	TryCmdArgs([]string) Builder
+}
+
+// fallback builds the fallback Cli that reads in, returns nil if no fallback source.
+func (c *tryCli) fallback(in io.Reader) Cli {
+	b := c.fallbackBuilder
+	fallback := b.InStream(in).Build()
+	if _, ok := fallback.(*baseCli); ok {
+		return nil
+	}
+	return fallback
+}
+
+func (c *tryCli) Run(ctx context.Context) (exitCode int, err error) {
+	// primary を実行する前に確認する.
+	if c.fallback(nil) == nil {
+		return 1, fmt.Errorf("tryCli.Run no fallback source")
+	}
+	cmd, err := c.command(ctx, c.tryCmdArgs, "", nil)
+	if err != nil {
+		return 1, errors.Wrapf(err, "tryCli.Run command args(%s)", c.tryCmdArgs)
+	}
+	cmd.Stdin = c.inStream
+	cmd.Stderr = c.errStream
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 1, errors.Wrapf(err, "tryCli.Run stdout pipe args(%s)", c.tryCmdArgs)
+	}
+	var output io.Reader = stdout
+	wait := cmd.Wait
+	kill := func() { cmd.Process.Kill() }
+	if err := cmd.Start(); err != nil {
+		// 起動できない場合は出力のない primary として扱う(失敗した場合と同じく fallback へ切り替える).
+		fmt.Fprintf(c.errStream, "Warning in tryCli: %s\n", errors.Wrapf(err, "tryCli.Run start args(%s)", c.tryCmdArgs))
+		output = bytes.NewReader(nil)
+		wait = func() error { return nil }
+		kill = func() {}
+	}
+
+	var sniffed []byte
+	r, done, err := c.switchInput(ctx, c.teiBuilder.OnSwitch(func(_ tei.Reason, b []byte) {
+		sniffed = b
+	}).Standby(func() io.Reader {
+		return nil
+	}), output)
+	defer done()
+	if err != nil {
+		kill()
+		wait()
+		return errExitCode(err), errors.Wrapf(err, "tryCli.Run switching the output of args(%s)", c.tryCmdArgs)
+	}
+
+	if r == nil {
+		// primary の終了コードは無視して fallback へ切り替える.
+		// --json-path の場合は出力の残りが読まれていないので、primary が書き込みで止まらないように読み捨てる.
+		io.Copy(ioutil.Discard, output)
+		wait()
+		// sniff した内容を再現すると fallback 側でも同じ理由で切り替わる.
+		return c.fallback(bytes.NewReader(sniffed)).Run(ctx)
+	}
+
+	_, copyErr := io.Copy(c.outStream, r)
+	waitErr := wait()
+	if copyErr != nil {
+		return 1, errors.Wrapf(copyErr, "tryCli.Run reading the output of args(%s)", c.tryCmdArgs)
+	}
+	if waitErr != nil {
+		// 出力を開始した後なので fallback へは切り替えられない.
+		if exitErr, ok := waitErr.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+			return exitErr.ExitCode(), errors.Wrapf(waitErr, "tryCli.Run wait args(%s)", c.tryCmdArgs)
+		}
+		return 1, errors.Wrapf(waitErr, "tryCli.Run wait args(%s)", c.tryCmdArgs)
+	}
+	return 0, nil
+}
+
+func newTryCli(b *builder) *tryCli {
+	fallbackBuilder := *b
+	fallbackBuilder.tryCmdArgs = nil
+	// 入力の保存と再生、ロックは tryCli 側で行う(同じファイルを二重にロックしない).
+	fallbackBuilder.remember = ""
+	fallbackBuilder.lock = ""
+	return &tryCli{
+		runCli:          *newRunCli(b),
+		tryCmdArgs:      b.tryCmdArgs,
+		fallbackBuilder: fallbackBuilder,
+	}
+}
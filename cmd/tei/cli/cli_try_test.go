@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tryCli_Run(t *testing.T) {
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name          string
+		builder       Builder
+		args          args
+		want          string
+		wantErrStream string
+		wantExitCode  int
+		wantErr       bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmd(), "primary"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd: primary\n",
+		}, {
+			name: "input",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				TryCmdArgs([]string{"cat"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "no data",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				TryCmdArgs([]string{"cat"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd: fallback\n",
+		}, {
+			name: "newline",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				TryCmdArgs([]string{"echo"}).
+				CmdArgs([]string{testStandbyCmdEnv()}).
+				Chdir("/").
+				ClearEnv(true),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd env: / newline 1   \n",
+		}, {
+			name: "primary error",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmdErr(), "primary"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "standby cmd: fallback\n",
+		}, {
+			name: "primary error after data",
+			builder: NewBuilder().
+				TryCmdArgs([]string{"/bin/sh", "-c", "echo primary; exit 3"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "primary\n",
+			wantExitCode: 3,
+			wantErr:      true,
+		}, {
+			name: "primary not exist",
+			builder: NewBuilder().
+				TryCmdArgs([]string{"./not_exist"}).
+				CmdArgs([]string{testStandbyCmd(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:          "standby cmd: fallback\n",
+			wantErrStream: "Warning in tryCli: tryCli.Run start args([./not_exist]): ",
+		}, {
+			name: "fallback file",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmdErr()}).
+				File(testStandbyFile()),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "file data\n",
+		}, {
+			name: "fallback string",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmdErr()}).
+				String("string data\n"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "string data\n",
+		}, {
+			name: "fallback error",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmdErr()}).
+				CmdArgs([]string{testStandbyCmdErr(), "fallback"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "no fallback before primary",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmd(), "primary"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "no fallback",
+			builder: NewBuilder().
+				TryCmdArgs([]string{testStandbyCmdErr()}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(errStream).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("tryCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("tryCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "tryCli.Run() outStream")
+			if tt.wantErrStream != "" {
+				assert.Contains(t, errStream.String(), tt.wantErrStream, "tryCli.Run() errStream")
+			}
+		})
+	}
+}
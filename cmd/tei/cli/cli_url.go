@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+type urlCli struct {
+	baseCli
+	url         string
+	urlHeaders  []string
+	urlTimeout  time.Duration
+	urlCacheDir string
+}
+
+// URLCliBuilder adds a property to CliBuilder.
+type URLCliBuilder interface {
+	// URL sets the URL that is fetched by GET as the standby source.
+	URL(string) Builder
+	// URLHeaders sets the request headers("Key: Value").
+	URLHeaders([]string) Builder
+	// URLTimeout sets the timeout of the request including reading the body(0 is no timeout).
+	URLTimeout(time.Duration) Builder
+	// URLCacheDir sets the directory that the response is cached(by ETag/Last-Modified) in.
+	URLCacheDir(string) Builder
+}
+
+// urlCacheMeta is the validators of the cached response.
+type urlCacheMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// urlCache stores the response in the directory.
+//
+// ファイル名は URL とリクエストヘッダーの sha256 から決める.
+// meta と body は 1 つのファイルに保存する(1 行目が meta の JSON、それ以降が body).
+type urlCache struct {
+	dir  string
+	name string
+}
+
+func newURLCache(dir string, url string, headers []string) *urlCache {
+	// ヘッダーの順番はキーに影響させない.
+	h := append([]string{}, headers...)
+	sort.Strings(h)
+	sum := sha256.Sum256([]byte(strings.Join(append([]string{url}, h...), "\n")))
+	return &urlCache{
+		dir:  dir,
+		name: filepath.Join(dir, hex.EncodeToString(sum[:])),
+	}
+}
+
+// readMeta reads the validators from the head of the cached file, returns the size of the head.
+func readMeta(file *os.File) (*urlCacheMeta, int64, error) {
+	line, err := bufio.NewReader(file).ReadBytes('\n')
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "urlCache read meta")
+	}
+	m := &urlCacheMeta{}
+	if err := json.Unmarshal(line, m); err != nil {
+		return nil, 0, errors.Wrapf(err, "urlCache unmarshal meta")
+	}
+	return m, int64(len(line)), nil
+}
+
+// meta returns the validators of the cached response, returns nil if not cached.
+func (u *urlCache) meta() *urlCacheMeta {
+	file, err := os.Open(u.name)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	m, _, err := readMeta(file)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// open opens the cached file, the returned file is positioned at the body.
+func (u *urlCache) open() (*os.File, error) {
+	file, err := os.Open(u.name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "urlCache.open open cached file")
+	}
+	_, n, err := readMeta(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(n, io.SeekStart); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "urlCache.open seek to body")
+	}
+	return file, nil
+}
+
+// writer returns the writer of the temporary file and the function to commit it.
+//
+// meta は body の前に書き込まれる.
+// commit されるまでは既存のキャッシュは置き換えない(1 回の rename で meta と body を置き換える).
+func (u *urlCache) writer(m *urlCacheMeta) (w io.Writer, commit func() error, abort func(), err error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "urlCache.writer marshal meta")
+	}
+	tmp, commit, abort, err := cacheWriter(u.dir, u.name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		abort()
+		return nil, nil, nil, errors.Wrapf(err, "urlCache.writer write meta")
+	}
+	return tmp, commit, abort, nil
+}
+
+// request builds the request with the headers and the validators of the cache.
+func (c *urlCli) request(ctx context.Context, m *urlCacheMeta) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "urlCli.Run new request")
+	}
+	req = req.WithContext(ctx)
+	for _, h := range c.urlHeaders {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("urlCli.Run invalid header: %s", h)
+		}
+		req.Header.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+	if m != nil {
+		if m.ETag != "" {
+			req.Header.Set("If-None-Match", m.ETag)
+		}
+		if m.LastModified != "" {
+			req.Header.Set("If-Modified-Since", m.LastModified)
+		}
+	}
+	return req, nil
+}
+
+func (c *urlCli) Run(ctx context.Context) (exitCode int, err error) {
+	closers := []func(){}
+	defer func() {
+		for _, f := range closers {
+			f()
+		}
+	}()
+
+	c.teiBuilder = c.teiBuilder.Standby(func() io.Reader {
+		var cache *urlCache
+		var m *urlCacheMeta
+		if c.urlCacheDir != "" {
+			cache = newURLCache(c.urlCacheDir, c.url, c.urlHeaders)
+			m = cache.meta()
+		}
+		// openCache は取得できなかった場合に代わりにキャッシュを返す.
+		openCache := func(cause error) io.Reader {
+			file, err := cache.open()
+			if err != nil {
+				return tei.ErrReader(cause)
+			}
+			closers = append(closers, func() { file.Close() })
+			return file
+		}
+
+		req, err := c.request(ctx, m)
+		if err != nil {
+			return tei.ErrReader(err)
+		}
+		client := &http.Client{Timeout: c.urlTimeout}
+		resp, err := client.Do(req)
+		if err != nil {
+			err = errors.Wrapf(err, "urlCli.Run get url")
+			if m != nil {
+				// オフラインでもキャッシュがあれば利用する.
+				fmt.Fprintf(c.errStream, "Warning in urlCli: using the cached response: %s\n", err)
+				return openCache(err)
+			}
+			return tei.ErrReader(err)
+		}
+		closers = append(closers, func() { resp.Body.Close() })
+
+		switch {
+		case resp.StatusCode == http.StatusNotModified && m != nil:
+			return openCache(fmt.Errorf("urlCli.Run cached body is not found: %s", c.url))
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return tei.ErrReader(fmt.Errorf("urlCli.Run unexpected status: %s", resp.Status))
+		}
+
+		if cache == nil {
+			return resp.Body
+		}
+		etag := resp.Header.Get("ETag")
+		lastModified := resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			// 検証できないレスポンスはキャッシュしない.
+			return resp.Body
+		}
+		w, commit, abort, err := cache.writer(&urlCacheMeta{
+			URL:          c.url,
+			ETag:         etag,
+			LastModified: lastModified,
+		})
+		if err != nil {
+			fmt.Fprintf(c.errStream, "Warning in urlCli: the response is not cached: %s\n", err)
+			return resp.Body
+		}
+		r := &cachingReader{r: resp.Body, w: w, commit: commit, abort: abort, warn: func(err error) {
+			fmt.Fprintf(c.errStream, "Warning in urlCli: the response is not cached: %s\n", err)
+		}}
+		closers = append(closers, r.close)
+		return r
+	})
+	return c.baseCli.Run(ctx)
+}
+
+func newURLCli(b *builder) *urlCli {
+	return &urlCli{
+		baseCli:     *newBaseCli(b),
+		url:         b.url,
+		urlHeaders:  b.urlHeaders,
+		urlTimeout:  b.urlTimeout,
+		urlCacheDir: b.urlCacheDir,
+	}
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testURLServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("url data\n"))
+	})
+	mux.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("header: " + r.Header.Get("X-Test") + "\n"))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("slow data\n"))
+	})
+	mux.HandleFunc("/not_found", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	return httptest.NewServer(mux)
+}
+
+func Test_urlCli_Run(t *testing.T) {
+	ts := testURLServer()
+	defer ts.Close()
+
+	type args struct {
+		ctx context.Context
+	}
+	tests := []struct {
+		name         string
+		builder      Builder
+		args         args
+		want         string
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			builder: NewBuilder().
+				InStream(strings.NewReader("input data")).
+				URL(ts.URL + "/data"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "input data",
+		}, {
+			name: "standby",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				URL(ts.URL + "/data"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "url data\n",
+		}, {
+			name: "headers",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				URL(ts.URL + "/header").
+				URLHeaders([]string{"X-Test: test value"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want: "header: test value\n",
+		}, {
+			name: "invalid header",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				URL(ts.URL + "/header").
+				URLHeaders([]string{"X-Test"}),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "not found",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				URL(ts.URL + "/not_found"),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		}, {
+			name: "timeout",
+			builder: NewBuilder().
+				InStream(strings.NewReader("")).
+				URL(ts.URL + "/slow").
+				URLTimeout(50 * time.Millisecond),
+			args: args{
+				ctx: context.Background(),
+			},
+			want:         "",
+			wantExitCode: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outStream := &strings.Builder{}
+			c := tt.builder.
+				OutStream(outStream).
+				ErrStream(ioutil.Discard).
+				Build()
+			gotExitCode, err := c.Run(tt.args.ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("urlCli.Run() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotExitCode != tt.wantExitCode {
+				t.Errorf("urlCli.Run() = %v, want %v", gotExitCode, tt.wantExitCode)
+			}
+			assert.Equal(t, tt.want, outStream.String(), "urlCli.Run() outStream")
+		})
+	}
+}
+
+func Test_urlCli_Run_cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hits := map[int]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			hits[http.StatusNotModified]++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		hits[http.StatusOK]++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("url data" + r.Header.Get("X-Test") + "\n"))
+	}))
+	url := ts.URL + "/data"
+
+	run := func(headers ...string) (string, string, int, error) {
+		outStream := &strings.Builder{}
+		errStream := &strings.Builder{}
+		exitCode, err := NewBuilder().
+			InStream(strings.NewReader("")).
+			OutStream(outStream).
+			ErrStream(errStream).
+			URL(url).
+			URLHeaders(headers).
+			URLTimeout(time.Second).
+			URLCacheDir(dir).
+			Build().
+			Run(context.Background())
+		return outStream.String(), errStream.String(), exitCode, err
+	}
+
+	// 初回は取得してキャッシュする.
+	out, errOut, exitCode, err := run()
+	assert.Equal(t, "url data\n", out, "urlCli.Run() outStream(fetch)")
+	assert.Equal(t, "", errOut, "urlCli.Run() errStream(fetch)")
+	assert.Equal(t, 0, exitCode, "urlCli.Run() exitCode(fetch)")
+	assert.Nil(t, err, "urlCli.Run() err(fetch)")
+
+	// 2 回目は 304 でキャッシュを利用する.
+	out, errOut, exitCode, err = run()
+	assert.Equal(t, "url data\n", out, "urlCli.Run() outStream(not modified)")
+	assert.Equal(t, "", errOut, "urlCli.Run() errStream(not modified)")
+	assert.Equal(t, 0, exitCode, "urlCli.Run() exitCode(not modified)")
+	assert.Nil(t, err, "urlCli.Run() err(not modified)")
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusNotModified: 1}, hits, "hits")
+
+	// リクエストヘッダーが異なる場合は別のキャッシュになる.
+	out, errOut, exitCode, err = run("X-Test: foo")
+	assert.Equal(t, "url datafoo\n", out, "urlCli.Run() outStream(headers)")
+	assert.Equal(t, "", errOut, "urlCli.Run() errStream(headers)")
+	assert.Equal(t, 0, exitCode, "urlCli.Run() exitCode(headers)")
+	assert.Nil(t, err, "urlCli.Run() err(headers)")
+	out, _, _, _ = run("X-Test: foo")
+	assert.Equal(t, "url datafoo\n", out, "urlCli.Run() outStream(headers not modified)")
+	assert.Equal(t, map[int]int{http.StatusOK: 2, http.StatusNotModified: 2}, hits, "hits(headers)")
+
+	// meta と body は 1 つのファイルに保存される.
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(files), "cached files")
+
+	// オフラインでもキャッシュを利用する.
+	ts.Close()
+	out, errOut, exitCode, err = run()
+	assert.Equal(t, "url data\n", out, "urlCli.Run() outStream(offline)")
+	assert.Contains(t, errOut, "Warning in urlCli: using the cached response: ", "urlCli.Run() errStream(offline)")
+	assert.Equal(t, 0, exitCode, "urlCli.Run() exitCode(offline)")
+	assert.Nil(t, err, "urlCli.Run() err(offline)")
+}
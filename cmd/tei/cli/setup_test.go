@@ -26,6 +26,22 @@ func testStandbyFile() string {
 	return filepath.Join(cwd, "testdata", "standby.txt")
 }
 
+func testStandbyFile2() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby2.txt")
+}
+
+func testNewlineFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "newline.txt")
+}
+
 func testStandbyCmd() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -50,6 +66,38 @@ func testStandbyCmdErrOut() string {
 	return filepath.Join(cwd, "testdata", "standby_err_out.sh")
 }
 
+func testStandbyCmdCat() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby_cat.sh")
+}
+
+func testStandbyCmdEnv() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby_env.sh")
+}
+
+func testStandbyEnvFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby.env")
+}
+
+func testStandbyTemplateFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby.tmpl")
+}
+
 func TestMain(m *testing.M) {
 	goleak.VerifyTestMain(m)
 }
@@ -0,0 +1,3 @@
+#!/bin/sh
+
+echo "standby cmd env: $(pwd) ${TEI_REASON} ${TEI_SNIFFED_BYTES} ${TEST_VAR1} ${TEST_VAR2} ${HOME}"
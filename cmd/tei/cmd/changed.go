@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newChangedCmd(builders globalBuildersFunc) *cobra.Command {
+	var dir string
+	var update bool
+	var passThrough bool
+	// changedCmd represents the changed command
+	cmd := &cobra.Command{
+		Use:          "changed [flags] <key>",
+		SilenceUsage: true,
+		Short:        "Check the piped input is changed from the last time",
+		Long: `changed compares the digest(sha256) of the piped input with the digest stored under the key.
+
+The input is not buffered, and no data from the piped input(e.g. a lone newline) is compared as is.
+The digest is replaced atomically only if --update is specified and the input is changed.
+
+Exit codes:
+  0  the input is changed(or the digest is not stored yet)
+  1  the input is not changed
+  2  error(e.g. invalid key, read error)
+
+The global flags --remember* and --lock* can not be used(they are rejected).
+`,
+		Example: `  $ curl -s https://example.com/feed | ` + cmdName + ` changed -u feed && notify-send "feed updated"
+  $ curl -s https://example.com/feed | ` + cmdName + ` changed -u -p feed | tee feed.xml`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return rejectFlags(cmd, rememberLockFlags)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			changedCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Changed(true).
+				ChangedKey(args[0]).
+				ChangedDir(dir).
+				ChangedUpdate(update).
+				ChangedPassThrough(passThrough).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), changedCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringVar(&dir, "dir", "", `the directory that the digest is stored in(default "$XDG_CACHE_HOME/tei/changed")`)
+	flags.BoolVarP(&update, "update", "u", false, "update the stored digest")
+	flags.BoolVarP(&passThrough, "pass-through", "p", false, "pass-through the input to stdout")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newChangedCmd(builders))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newCheckCmd(builders globalBuildersFunc) *cobra.Command {
+	var minBytes int
+	var minLines int
+	var match []string
+	var noMatch []string
+	var jsonValid bool
+	var utf8Valid bool
+	var passThrough bool
+	var report string
+	var reportFile string
+	// checkCmd represents the check command
+	cmd := &cobra.Command{
+		Use:          "check [flags]",
+		SilenceUsage: true,
+		Short:        "Check the piped input by the predicates",
+		Long: `check checks the piped input by the predicates.
+
+No data from the piped input always fails, and all the predicates must be satisfied.
+
+Exit codes:
+  0  the input satisfies the predicates
+  1  no data from the input, or the input does not satisfy the predicates
+  2  error(e.g. invalid regular expression, read error)
+
+With --pass-through, the input is written to stdout as is(even if it is a lone newline) while it is read.
+The whole input is kept in memory only with --match, --no-match or --json-valid.
+
+With --report, the classification of the input is written to stderr(or --report-file).
+  json  a JSON object(empty, reason, sniffed_bytes, input_bytes, first_line, input_type, pass_through, passed, exit_code)
+  env   "TEI_*=value" lines that can be evaluated by the shell
+sniffed_bytes(TEI_SNIFFED_BYTES) is the size(bytes) of the input that was read to classify it,
+and input_bytes(TEI_INPUT_BYTES) is the size(bytes) of the whole input.
+
+The global flags --remember* and --lock* can not be used(they are rejected).
+`,
+		Example: `  $ echo "input data" | ` + cmdName + ` check                            # exit code = 0
+  $ echo "" | ` + cmdName + ` check                                      # exit code = 1
+  $ echo '{"a": 1}' | ` + cmdName + ` check --json-valid -p | jq .a      # 1
+  $ echo "status: ok" | ` + cmdName + ` check --match '^status: ok$' --no-match error
+  $ echo "" | ` + cmdName + ` check --report env --report-file report.env; . ./report.env; echo "${TEI_REASON}" # newline`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return rejectFlags(cmd, rememberLockFlags)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			checkCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Check(true).
+				CheckMinBytes(minBytes).
+				CheckMinLines(minLines).
+				CheckMatch(match).
+				CheckNoMatch(noMatch).
+				CheckJSONValid(jsonValid).
+				CheckUTF8Valid(utf8Valid).
+				CheckPassThrough(passThrough).
+				CheckReport(report).
+				CheckReportFile(reportFile).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), checkCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.IntVar(&minBytes, "min-bytes", 0, "the minimum size(bytes) of the input")
+	flags.IntVar(&minLines, "min-lines", 0, "the minimum number of lines of the input")
+	flags.StringArrayVar(&match, "match", nil, "the regular expression that must match the input")
+	flags.StringArrayVar(&noMatch, "no-match", nil, "the regular expression that must not match the input")
+	flags.BoolVar(&jsonValid, "json-valid", false, "the input must be valid JSON")
+	flags.BoolVar(&utf8Valid, "utf8-valid", false, "the input must be valid UTF-8")
+	flags.BoolVarP(&passThrough, "pass-through", "p", false, "pass-through the input to stdout")
+	flags.StringVar(&report, "report", "", "write the report of the input(json, env)")
+	flags.StringVar(&reportFile, "report-file", "", "the file that the report is written to(default stderr)")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newCheckCmd(builders))
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/cmd/tei/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newCheckCmd(t *testing.T) {
+	teiBuilder := tei.NewBuilder()
+	type args struct {
+		teiBuilder tei.Builder
+		cliBuilder cli.Builder
+		args       []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    cli.Cli
+		wantErr bool
+	}{
+		{
+			name: "basic",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{},
+			},
+			want: cli.NewBuilder().
+				CmdName("check").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Check(true).
+				Build(),
+		}, {
+			name: "predicates",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args: []string{
+					"--min-bytes", "10", "--min-lines", "2",
+					"--match", "ok", "--match", "count", "--no-match", "error",
+					"--json-valid", "--utf8-valid", "-p",
+				},
+			},
+			want: cli.NewBuilder().
+				CmdName("check").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Check(true).
+				CheckMinBytes(10).
+				CheckMinLines(2).
+				CheckMatch([]string{"ok", "count"}).
+				CheckNoMatch([]string{"error"}).
+				CheckJSONValid(true).
+				CheckUTF8Valid(true).
+				CheckPassThrough(true).
+				Build(),
+		}, {
+			name: "report",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--report", "env", "--report-file", "report.env"},
+			},
+			want: cli.NewBuilder().
+				CmdName("check").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Check(true).
+				CheckReport(cli.CheckReportEnv).
+				CheckReportFile("report.env").
+				Build(),
+		}, {
+			name: "args=1",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"test"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveCmdExit := cmdExit
+			defer func() {
+				cmdExit = saveCmdExit
+			}()
+			cmdExit = func(int) {}
+			var spy = func(b *fakeCliBuilder) {
+				got := b.b.Build()
+				assert.Equal(t, tt.want, got, "cli.Builder.Build() in cmd")
+			}
+			builders := func(tei.Builder, cli.Builder) (tei.Builder, cli.Builder) {
+				return tt.args.teiBuilder, tt.args.cliBuilder.(FakeCliBuilder).SetSpy(spy)
+			}
+			c := newCheckCmd(builders)
+			c.SetArgs(tt.args.args)
+			c.SetOutput(ioutil.Discard)
+			err := c.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
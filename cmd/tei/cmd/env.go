@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newEnvCmd(builders globalBuildersFunc) *cobra.Command {
+	var doNotNewLine bool
+	var required bool
+	// envCmd represents the env command
+	cmd := &cobra.Command{
+		Use:          "env [flags] <name>",
+		SilenceUsage: true,
+		Short:        "Switch the piped input to \"echo $name\"",
+		Long: `env switch the piped input to "echo $name" if no data from the piped input.
+
+The value is not passed via the command line arguments,
+so it is not exposed in ps and can start with dashes.
+`,
+		Example: `  $ ` + cmdName + ` env DEFAULT_PAYLOAD                         # echo "$DEFAULT_PAYLOAD"
+  $ echo "" | ` + cmdName + ` env DEFAULT_PAYLOAD               # echo "$DEFAULT_PAYLOAD"
+  $ echo "input data" | ` + cmdName + ` env DEFAULT_PAYLOAD     # input data`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			envCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				EnvVar(args[0]).
+				EnvVarNewline(doNotNewLine == false).
+				EnvVarRequired(required).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), envCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.BoolVarP(&doNotNewLine, "n", "n", false, "do not output the trailing newline")
+	flags.BoolVarP(&required, "required", "r", false, "exit with error if the variable is unset")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newEnvCmd(builders))
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func newFdCmd(builders globalBuildersFunc) *cobra.Command {
+	// fdCmd represents the fd command
+	cmd := &cobra.Command{
+		Use:                   "fd <fd>",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Short:                 "Switch the piped input to \"cat <&fd\"",
+		Long: `fd switch the piped input to "cat <&fd"(the file descriptor already opened) if no data from the piped input.
+`,
+		Example: `  $ ` + cmdName + ` fd 3 3< standby_data.txt                           # cat standby_data.txt
+  $ echo -n "" | ` + cmdName + ` fd 3 3< <(echo "standby data")       # standby data
+  $ echo -n "input data" | ` + cmdName + ` fd 3 3< standby_data.txt    # input data`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			fd, err := strconv.Atoi(args[0])
+			if err != nil || fd < 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error in parsing fd from args: %s\n", args[0])
+				cmdExit(1)
+				return
+			}
+			teiBuilder, cliBuilder := builders(nil, nil)
+			fdCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Fd(fd).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), fdCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newFdCmd(builders))
+}
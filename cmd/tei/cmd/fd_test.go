@@ -0,0 +1,221 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/hankei6km/go-tei"
+	"github.com/hankei6km/go-tei/cmd/tei/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStandbyFd returns the file descriptor of the standby file,
+// the descriptor is owned by the caller.
+func testStandbyFd() int {
+	file, err := os.Open(testStandbyFile())
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	fd, err := syscall.Dup(int(file.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	return fd
+}
+
+func Test_newFdCmd(t *testing.T) {
+	teiBuilder := tei.NewBuilder()
+	type args struct {
+		teiBuilder tei.Builder
+		cliBuilder cli.Builder
+		args       []string
+	}
+	tests := []struct {
+		name         string
+		args         args
+		want         cli.Cli
+		wantExitCode int
+		wantErr      bool
+	}{
+		{
+			name: "basic",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"3"},
+			},
+			want: cli.NewBuilder().
+				CmdName("fd").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Fd(3).
+				Build(),
+		}, {
+			name: "zero",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"0"},
+			},
+			want: cli.NewBuilder().
+				CmdName("fd").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Fd(0).
+				Build(),
+		}, {
+			name: "parse err",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"three"},
+			},
+			wantExitCode: 1,
+		}, {
+			name: "negative",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--", "-1"},
+			},
+			wantExitCode: 1,
+		}, {
+			name: "args=0",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveCmdExit := cmdExit
+			defer func() {
+				cmdExit = saveCmdExit
+			}()
+			cmdExit = func(exitCode int) {
+				assert.Equal(t, tt.wantExitCode, exitCode, "exit code")
+			}
+			var spy = func(b *fakeCliBuilder) {
+				got := b.b.Build()
+				assert.Equal(t, tt.want, got, "cli.Builder.Build() in cmd")
+			}
+			builders := func(tei.Builder, cli.Builder) (tei.Builder, cli.Builder) {
+				return tt.args.teiBuilder, tt.args.cliBuilder.(FakeCliBuilder).SetSpy(spy)
+			}
+			c := newFdCmd(builders)
+			c.SetArgs(tt.args.args)
+			c.SetOutput(ioutil.Discard)
+			err := c.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_main_fd(t *testing.T) {
+	type args struct {
+		args  func() []string
+		input io.Reader
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantOutText  string
+		wantErrText  string
+		wantExitCode int
+	}{
+		{
+			name: "fd: basic",
+			args: args{
+				args: func() []string {
+					return []string{"fd", strconv.Itoa(testStandbyFd())}
+				},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `file data
+`,
+		}, {
+			name: "fd: data",
+			args: args{
+				args: func() []string {
+					return []string{"fd", "9999"}
+				},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText: `input data`,
+		}, {
+			name: "input-fd: some data",
+			args: args{
+				args: func() []string {
+					return []string{"--input-fd", strconv.Itoa(testStandbyFd()), "1"}
+				},
+				input: strings.NewReader(""),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "input-fd: string",
+			args: args{
+				args: func() []string {
+					return []string{"--input-fd", strconv.Itoa(testStandbyFd()), "string", "standby", "data"}
+				},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `file data
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveCmdExit := cmdExit
+			defer func() {
+				cmdExit = saveCmdExit
+			}()
+			teiBuilder := tei.NewBuilder()
+			cliBuilder := cli.NewBuilder()
+			builders := func(t tei.Builder, c cli.Builder) (tei.Builder, cli.Builder) {
+				if t != nil {
+					teiBuilder = t
+				}
+				if c != nil {
+					cliBuilder = c
+				}
+				return teiBuilder, cliBuilder
+			}
+
+			c := newRootCmd(builders)
+			outStream := &strings.Builder{}
+			errStream := &strings.Builder{}
+			c.SetOut(outStream)
+			c.SetErr(errStream)
+
+			c.SetArgs(tt.args.args())
+			c.SetIn(tt.args.input)
+
+			c.AddCommand(newStringCmd(builders))
+			c.AddCommand(newFdCmd(builders))
+
+			cmdExit = func(exitCode int) {
+				assert.Equal(t, tt.wantExitCode, exitCode, "exit code from newRootCmd().Execute()")
+			}
+			c.Execute()
+			assert.Equal(t, tt.wantOutText, outStream.String(), "out from from newRootCmd().Execute()")
+			assert.Equal(t, tt.wantErrText, errStream.String(), "err from from newRootCmd().Execute()")
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newFifoCmd(builders globalBuildersFunc) *cobra.Command {
+	var timeout time.Duration
+	// fifoCmd represents the fifo command
+	cmd := &cobra.Command{
+		Use:          "fifo [flags] <path>",
+		SilenceUsage: true,
+		Short:        "Switch the piped input to the data from the named pipe",
+		Long: `fifo switch the piped input to the data from the named pipe if no data from the piped input.
+
+Opening the named pipe blocks until the writer opens it,
+use --timeout to give up waiting for the writer.
+`,
+		Example: `  $ ` + cmdName + ` fifo /tmp/standby.fifo                            # cat /tmp/standby.fifo
+  $ echo "" | ` + cmdName + ` fifo --timeout 5s /tmp/standby.fifo
+  $ echo "input data" | ` + cmdName + ` fifo /tmp/standby.fifo         # input data`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			fifoCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Fifo(args[0]).
+				FifoTimeout(timeout).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), fifoCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.DurationVarP(&timeout, "timeout", "", 0, "the timeout to wait for the writer(0 is no timeout)")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newFifoCmd(builders))
+}
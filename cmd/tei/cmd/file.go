@@ -2,29 +2,80 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/hankei6km/go-tei/cmd/tei/cli"
 	"github.com/spf13/cobra"
 )
 
 func newFileCmd(builders globalBuildersFunc) *cobra.Command {
+	var missing string
+	var concat bool
+	var sel string
+	var maxAge time.Duration
+	var stale string
 	// fileCmd represents the file command
 	cmd := &cobra.Command{
-		Use:                   "file <input_file>",
+		Use:                   "file [flags] <input_file>...",
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		Short:                 "Switch the piped input to \"cat file\"",
 		Long: `file switch the piped input to "cat /path/to/file" if no data from the piped input.
+
+If multiple files are passed, the first one that has data is used(the last one is used as is).
+The missing(or unreadable) file is an error by default, it can be skipped by --missing=skip.
+
+The file can be a glob pattern, the matched files are selected by --select.
+  first    each matched file is used as the candidate in lexical order (default)
+  newest   the newest matched file by mtime
+  oldest   the oldest matched file by mtime
+  last     the lexically last matched file
+  all      all the matched files are concatenated
+If nothing matches the pattern, it is treated as the missing file.
+
+With --max-age, the file older than the age(by mtime) is stale, it is handled by --stale.
+  warn     write the warning to stderr and use the file (default)
+  error    exit with the code ` + strconv.Itoa(cli.ExitCodeStale) + `
+  skip     skip to the next file
 `,
 		Example: `  $ ` + cmdName + ` file standby_data.txt                           # cat standby_data.txt
   $ echo -n "" | ` + cmdName + ` file standby_data.txt              # cat standby_data.txt
-  $ echo -n "input data" | ` + cmdName + ` file standby_data.txt    # input data`,
-		Args: cobra.ExactArgs(1),
+  $ echo -n "input data" | ` + cmdName + ` file standby_data.txt    # input data
+  $ ` + cmdName + ` file --missing=skip a.txt b.txt                 # cat a.txt (or b.txt if a.txt has no data)
+  $ ` + cmdName + ` file --concat a.txt b.txt                       # cat a.txt b.txt
+  $ ` + cmdName + ` file --select=newest 'snapshot-*.txt'           # cat the newest snapshot`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch missing {
+			case cli.FileMissingError, cli.FileMissingSkip:
+			default:
+				return fmt.Errorf("--missing must be skip or error: %s", missing)
+			}
+			switch sel {
+			case cli.FileSelectFirst, cli.FileSelectNewest, cli.FileSelectOldest, cli.FileSelectLast, cli.FileSelectAll:
+			default:
+				return fmt.Errorf("--select must be first, newest, oldest, last or all: %s", sel)
+			}
+			switch stale {
+			case cli.FileStaleWarn, cli.FileStaleError, cli.FileStaleSkip:
+			default:
+				return fmt.Errorf("--stale must be warn, error or skip: %s", stale)
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			teiBuilder, cliBuilder := builders(nil, nil)
 			fileCli := cliBuilder.
 				CmdName(cmd.Name()).
 				TeiBuilder(teiBuilder).
-				File(args[0]).
+				Files(args).
+				Missing(missing).
+				Concat(concat).
+				Select(sel).
+				MaxAge(maxAge).
+				Stale(stale).
 				InStream(cmd.InOrStdin()).
 				OutStream(cmd.OutOrStdout()).
 				ErrStream(cmd.ErrOrStderr()).
@@ -36,6 +87,11 @@ func newFileCmd(builders globalBuildersFunc) *cobra.Command {
 	flags := cmd.Flags()
 	flags.SetInterspersed(false)
 
+	flags.StringVar(&missing, "missing", "error", "policy for the missing file: skip|error")
+	flags.BoolVar(&concat, "concat", false, "concatenate all the files")
+	flags.StringVar(&sel, "select", "first", "strategy to select the files matched by the glob pattern: first|newest|oldest|last|all")
+	flags.DurationVar(&maxAge, "max-age", 0, "treat the file older than the age as stale(e.g. 24h)")
+	flags.StringVar(&stale, "stale", "warn", "policy for the stale file: warn|error|skip")
 	return cmd
 }
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/cmd/tei/cli"
@@ -35,6 +36,9 @@ func Test_newFileCmd(t *testing.T) {
 				OutStream(ioutil.Discard).
 				ErrStream(ioutil.Discard).
 				File("/path/to/test.txt").
+				Missing(cli.FileMissingError).
+				Select(cli.FileSelectFirst).
+				Stale(cli.FileStaleWarn).
 				Build(),
 		}, {
 			name: "args=0",
@@ -51,7 +55,69 @@ func Test_newFileCmd(t *testing.T) {
 				cliBuilder: NewFakeCliBuilder(),
 				args:       []string{"/path/to/test1.txt", "/path/to/test2.txt"},
 			},
-			wantErr: true,
+			want: cli.NewBuilder().
+				CmdName("file").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Files([]string{"/path/to/test1.txt", "/path/to/test2.txt"}).
+				Missing(cli.FileMissingError).
+				Select(cli.FileSelectFirst).
+				Stale(cli.FileStaleWarn).
+				Build(),
+		}, {
+			name: "missing concat",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--missing=skip", "--concat", "/path/to/test1.txt", "/path/to/test2.txt"},
+			},
+			want: cli.NewBuilder().
+				CmdName("file").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				Files([]string{"/path/to/test1.txt", "/path/to/test2.txt"}).
+				Missing(cli.FileMissingSkip).
+				Concat(true).
+				Select(cli.FileSelectFirst).
+				Stale(cli.FileStaleWarn).
+				Build(),
+		}, {
+			name: "select",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--select=newest", "/path/to/*.txt"},
+			},
+			want: cli.NewBuilder().
+				CmdName("file").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				File("/path/to/*.txt").
+				Missing(cli.FileMissingError).
+				Select(cli.FileSelectNewest).
+				Stale(cli.FileStaleWarn).
+				Build(),
+		}, {
+			name: "max age",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--max-age=24h", "--stale=skip", "/path/to/test.txt"},
+			},
+			want: cli.NewBuilder().
+				CmdName("file").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				File("/path/to/test.txt").
+				Missing(cli.FileMissingError).
+				Select(cli.FileSelectFirst).
+				MaxAge(24 * time.Hour).
+				Stale(cli.FileStaleSkip).
+				Build(),
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newIfDataCmd(builders globalBuildersFunc) *cobra.Command {
+	var noData bool
+	// ifDataCmd represents the ifdata command
+	cmd := &cobra.Command{
+		Use:                   "ifdata [-n] <command> [command_args]... [-- <alternative> [alternative_args]...]",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Short:                 "Run command with the piped input only if the piped input has data",
+		Long: `ifdata run "command [command_args]..." with the piped input only if the piped input has data,
+otherwise do nothing or run "alternative [alternative_args]...".
+The exit code of the command is propagated.
+`,
+		Example: `  $ echo "input data" | ` + cmdName + ` ifdata tr '[:lower:]' '[:upper:]'                 # INPUT DATA
+  $ echo "" | ` + cmdName + ` ifdata tr '[:lower:]' '[:upper:]'                           # (nothing)
+  $ echo "" | ` + cmdName + ` ifdata tr '[:lower:]' '[:upper:]' -- echo "no data"         # no data
+  $ echo "" | ` + cmdName + ` ifdata -n echo "no data"                                    # no data`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			ifDataCmdArgs, noDataCmdArgs := splitArgsAtDash(args)
+			if noData {
+				ifDataCmdArgs, noDataCmdArgs = noDataCmdArgs, ifDataCmdArgs
+			}
+			ifDataCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				IfDataCmdArgs(ifDataCmdArgs).
+				NoDataCmdArgs(noDataCmdArgs).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), ifDataCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.BoolVarP(&noData, "no-data", "n", false, "run the command only if no data from the piped input")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newIfDataCmd(builders))
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newLinesCmd(builders globalBuildersFunc) *cobra.Command {
+	var defaultValue string
+	var defaultEnv string
+	var template string
+	var null bool
+	// linesCmd represents the lines command
+	cmd := &cobra.Command{
+		Use:          "lines [flags]",
+		SilenceUsage: true,
+		Short:        "Replace the empty lines of the piped input with the default value",
+		Long: `lines replaces each empty(or whitespace-only) line of the piped input with the default value while streaming.
+
+The default value is chosen from --default, --env or --template(only one of them can be specified).
+The template is text/template, and the following fields can be used.
+  .Env   the environment variables
+  .Line  the line number(starts at 1)
+
+Non-empty lines are written as is.
+With -z, the lines are separated by NUL instead of newline.
+
+The global flags --json*, --csv*, --tsv, --remember* and --lock* are not applied(they are rejected).
+`,
+		Example: `  $ printf 'a\n\nb\n' | ` + cmdName + ` lines --default "-"
+  a
+  -
+  b
+  $ printf 'a\n\nb\n' | ` + cmdName + ` lines --template '(empty line {{.Line}})'
+  a
+  (empty line 2)
+  b`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return rejectFlags(cmd, transformIgnoredFlags)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			_, cliBuilder := builders(nil, nil)
+			linesCli := cliBuilder.
+				CmdName(cmd.Name()).
+				Lines(true).
+				LinesDefault(defaultValue).
+				LinesDefaultEnv(defaultEnv).
+				LinesDefaultTemplate(template).
+				LinesNull(null).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), linesCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringVarP(&defaultValue, "default", "d", "", "the default value of the empty line")
+	flags.StringVar(&defaultEnv, "env", "", "the name of the environment variable that has the default value")
+	flags.StringVar(&template, "template", "", "the text/template that is rendered as the default value")
+	flags.BoolVarP(&null, "null", "z", false, "the lines are separated by NUL instead of newline")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newLinesCmd(builders))
+}
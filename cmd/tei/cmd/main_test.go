@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -16,6 +18,14 @@ import (
 // 実際にコマンドを実行した場合とは若干挙動が異なる(TODO を参照)
 
 func Test_main(t *testing.T) {
+	os.Setenv("TEI_TEST_ENV_CMD", "-n env data")
+	os.Unsetenv("TEI_TEST_ENV_CMD_UNSET")
+	defer os.Unsetenv("TEI_TEST_ENV_CMD")
+	testTempDir, err := ioutil.TempDir("", "tei-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testTempDir)
 	type args struct {
 		args  []string
 		input io.Reader
@@ -182,6 +192,61 @@ func Test_main(t *testing.T) {
 			},
 			wantOutText: `
 `,
+		}, {
+			name: "file: files",
+			args: args{
+				args:  []string{"file", testDummyFile(), testStandbyFile2(), testStandbyFile()},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `file data 2
+`,
+		}, {
+			name: "file: --concat",
+			args: args{
+				args:  []string{"file", "--missing=skip", "--concat", testStandbyFile(), "not_exist", testStandbyFile2()},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `file data
+file data 2
+`,
+		}, {
+			name: "file: glob",
+			args: args{
+				args:  []string{"file", "--select=last", filepath.Join(filepath.Dir(testStandbyFile()), "standby*.txt")},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `file data 2
+`,
+		}, {
+			name: "file: stale",
+			args: args{
+				args:  []string{"file", "--max-age=1ns", "--stale=error", testStandbyFile()},
+				input: strings.NewReader(""),
+			},
+			wantErrText: `Error in runCli(file): Cli.Run reading the switched input: fileCli.Run the file is stale: ` + testStandbyFile() + `
+`,
+			wantExitCode: 3,
+		}, {
+			name: "file: invalid missing",
+			args: args{
+				args:  []string{"file", "--missing=nope", testStandbyFile()},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText: "Error: --missing must be skip or error: nope\n",
+		}, {
+			name: "file: invalid select",
+			args: args{
+				args:  []string{"file", "--select=bogus", testStandbyFile()},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText: "Error: --select must be first, newest, oldest, last or all: bogus\n",
+		}, {
+			name: "file: invalid stale",
+			args: args{
+				args:  []string{"file", "--stale=what", testStandbyFile()},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText: "Error: --stale must be warn, error or skip: what\n",
 		}, {
 			name: "file: file not exist",
 			args: args{
@@ -199,7 +264,7 @@ func Test_main(t *testing.T) {
 				input: os.Stdin,
 			},
 			// TODO: Args でのエラーが outSream になる原因調査(実際に動かすと stderr(2) へ出力されている).
-			wantOutText: `Error: accepts 1 arg(s), received 0
+			wantOutText: `Error: requires at least 1 arg(s), only received 0
 `,
 			wantExitCode: 1,
 		}, {
@@ -225,6 +290,389 @@ func Test_main(t *testing.T) {
 			},
 			wantOutText: `standby data
 `,
+		}, {
+			name: "string: remember save",
+			args: args{
+				args:  []string{"--remember", "test", "--remember-dir", testTempDir, "string", "standby", "data"},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText: `input data`,
+		}, {
+			name: "string: remember replay",
+			args: args{
+				args:  []string{"--remember", "test", "--remember-dir", testTempDir, "--remember-ttl", "1h", "string", "standby", "data"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `input data`,
+		}, {
+			name: "string: remember max size",
+			args: args{
+				args:  []string{"--remember", "test", "--remember-dir", testTempDir, "--remember-max-size", "5", "string", "standby", "data"},
+				input: strings.NewReader("input data 2"),
+			},
+			wantOutText: `input data 2`,
+		}, {
+			name: "string: remember not saved",
+			args: args{
+				args:  []string{"--remember", "test_other", "--remember-dir", testTempDir, "string", "standby", "data"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `standby data
+`,
+		}, {
+			name: "string: remember replay after max size",
+			args: args{
+				args:  []string{"--remember", "test", "--remember-dir", testTempDir, "string", "standby", "data"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `input data`,
+		}, {
+			name: "string: lock",
+			args: args{
+				args:  []string{"--lock", filepath.Join(testTempDir, "test.lock"), "--lock-mode", "no-wait", "string", "standby", "data"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `standby data
+`,
+		}, {
+			name: "check: basic",
+			args: args{
+				args:  []string{"check", "--json-valid", "-p"},
+				input: strings.NewReader(`{"a": 1}`),
+			},
+			wantOutText: `{"a": 1}`,
+		}, {
+			name: "check: failed",
+			args: args{
+				args:  []string{"check", "--json-valid", "-p"},
+				input: strings.NewReader(`{"a": 1`),
+			},
+			wantOutText:  `{"a": 1`,
+			wantExitCode: 1,
+		}, {
+			name: "check: newline",
+			args: args{
+				args:  []string{"check", "-p"},
+				input: strings.NewReader("\n"),
+			},
+			wantOutText:  "\n",
+			wantExitCode: 1,
+		}, {
+			name: "check: l=false newline",
+			args: args{
+				args:  []string{"-l=false", "check", "-p"},
+				input: strings.NewReader("\n"),
+			},
+			wantOutText: "\n",
+		}, {
+			name: "check: error",
+			args: args{
+				args:  []string{"check", "--match", "("},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText:  "Error in runCli(check): checkCli.Run compile regexp: error parsing regexp: missing closing ): `(`\n",
+			wantExitCode: 2,
+		}, {
+			name: "check: remember",
+			args: args{
+				args:  []string{"--remember", "test", "check", "--report", "json"},
+				input: strings.NewReader(""),
+			},
+			wantErrText: "Error: --remember can not be used with check\n",
+		}, {
+			name: "check: report",
+			args: args{
+				args:  []string{"check", "--report", "env"},
+				input: strings.NewReader("\n"),
+			},
+			wantErrText: `TEI_EMPTY=true
+TEI_REASON='newline'
+TEI_SNIFFED_BYTES=1
+TEI_INPUT_BYTES=1
+TEI_FIRST_LINE=''
+TEI_INPUT_TYPE='other'
+TEI_PASS_THROUGH=false
+TEI_PASSED=false
+TEI_EXIT_CODE=1
+`,
+			wantExitCode: 1,
+		}, {
+			name: "json: empty",
+			args: args{
+				args:  []string{"--json", "1"},
+				input: strings.NewReader(" [ ]\n"),
+			},
+			wantExitCode: 0,
+		}, {
+			name: "json: some data",
+			args: args{
+				args:  []string{"--json", "1"},
+				input: strings.NewReader(`["input data"]`),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "json: string",
+			args: args{
+				args:  []string{"--json", "string", "standby", "data"},
+				input: strings.NewReader("null\n"),
+			},
+			wantOutText: `standby data
+`,
+		}, {
+			name: "json: string some data",
+			args: args{
+				args:  []string{"--json", "string", "standby", "data"},
+				input: strings.NewReader(`{"a": 1}` + "\n"),
+			},
+			wantOutText: `{"a": 1}
+`,
+		}, {
+			name: "json: empty values",
+			args: args{
+				args:  []string{"--json", "--json-empty", `{"items":[]}`, "--json-empty", "[", "1"},
+				input: strings.NewReader(`{"items": []}`),
+			},
+			wantErrText: `Warning: invalid JSON value in --json-empty: [
+`,
+			wantExitCode: 0,
+		}, {
+			name: "json: limit",
+			args: args{
+				args:  []string{"--json", "--json-limit", "3", "1"},
+				input: strings.NewReader("[  ]"),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "json: path empty",
+			args: args{
+				args:  []string{"--json-path", ".items", "string", "standby", "data"},
+				input: strings.NewReader(`{"items": [], "next": null}`),
+			},
+			wantOutText: `standby data
+`,
+		}, {
+			name: "json: path missing",
+			args: args{
+				args:  []string{"--json-path", ".items", "1"},
+				input: strings.NewReader(`{"next": null}`),
+			},
+			wantExitCode: 0,
+		}, {
+			name: "json: path some data",
+			args: args{
+				args:  []string{"--json-path", ".items", "string", "standby", "data"},
+				input: strings.NewReader(`{"items": ["input data"], "next": null}`),
+			},
+			wantOutText: `{"items": ["input data"], "next": null}`,
+		}, {
+			name: "json: invalid path",
+			args: args{
+				args:  []string{"--json-path", "items", "string", "standby", "data"},
+				input: strings.NewReader(`{"items": []}`),
+			},
+			wantErrText:  "Error in runCli(string): Cli.Run reading the switched input: baseTei.Switch parsing the JSON path: parseJSONPath: the path must start with '.': items\n",
+			wantExitCode: 1,
+		}, {
+			name: "csv: header only",
+			args: args{
+				args:  []string{"--csv", "1"},
+				input: strings.NewReader("id,name\n"),
+			},
+			wantExitCode: 0,
+		}, {
+			name: "csv: some data",
+			args: args{
+				args:  []string{"--csv", "1"},
+				input: strings.NewReader("id,name\n1,input data\n"),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "csv: input header",
+			args: args{
+				args:  []string{"--csv", "--csv-fallback", "input-header", "string", "ID,NAME\n1,standby data"},
+				input: strings.NewReader("id,name\n"),
+			},
+			wantOutText: `id,name
+1,standby data
+`,
+		}, {
+			name: "csv: check header",
+			args: args{
+				args:  []string{"--tsv", "--csv-fallback", "check-header", "string", "id\tname\n1\tstandby data"},
+				input: strings.NewReader("id\tname\n"),
+			},
+			wantOutText: "id\tname\n1\tstandby data\n",
+		}, {
+			name: "csv: check header mismatch",
+			args: args{
+				args:  []string{"--csv", "--csv-fallback", "check-header", "string", "ID,NAME\n1,standby data"},
+				input: strings.NewReader("id,name\n"),
+			},
+			wantErrText:  "Error in runCli(string): Cli.Run reading the switched input: csvStandbyReader the header of the standby source does not match: [\"ID\" \"NAME\"], want [\"id\" \"name\"]\n",
+			wantExitCode: 1,
+		}, {
+			name: "json: path limit min",
+			args: args{
+				args:  []string{"--json-path", ".items", "--json-limit", "3", "string", "standby", "data"},
+				input: strings.NewReader(`{"items":[1]}` + "\n"),
+			},
+			wantOutText: `{"items":[1]}
+`,
+		}, {
+			name: "json: invalid limit",
+			args: args{
+				args:  []string{"--json-path", ".items", "--json-limit", "1", "string", "standby", "data"},
+				input: strings.NewReader(`{"items":[1]}` + "\n"),
+			},
+			wantErrText: "Error: --json-limit must be 3 or more: 1\n",
+		}, {
+			name: "csv: limit min",
+			args: args{
+				args:  []string{"--csv", "--csv-limit", "3", "string", "standby", "data"},
+				input: strings.NewReader("a,b\n1,2\n"),
+			},
+			wantOutText: "a,b\n1,2\n",
+		}, {
+			name: "csv: invalid limit",
+			args: args{
+				args:  []string{"--csv", "--csv-limit", "1", "string", "standby", "data"},
+				input: strings.NewReader("a,b\n1,2\n"),
+			},
+			wantErrText: "Error: --csv-limit must be 3 or more: 1\n",
+		}, {
+			name: "lock: invalid mode",
+			args: args{
+				args:  []string{"--lock", "test.lock", "--lock-mode", "bogus", "string", "standby", "data"},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText: "Error: --lock-mode must be wait or no-wait: bogus\n",
+		}, {
+			name: "csv: invalid fallback",
+			args: args{
+				args:  []string{"--csv", "--csv-fallback", "bogus", "string", "standby", "data"},
+				input: strings.NewReader("a,b\n1,2\n"),
+			},
+			wantErrText: "Error: --csv-fallback must be check-header or input-header: bogus\n",
+		}, {
+			name: "json: disabled",
+			args: args{
+				args:  []string{"1"},
+				input: strings.NewReader("[]"),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "changed: first time",
+			args: args{
+				args:  []string{"changed", "--dir", testTempDir, "-u", "-p", "test"},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText: "input data",
+		}, {
+			name: "changed: unchanged",
+			args: args{
+				args:  []string{"changed", "--dir", testTempDir, "-u", "-p", "test"},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText:  "input data",
+			wantExitCode: 1,
+		}, {
+			name: "changed: remember",
+			args: args{
+				args:  []string{"--remember", "test", "changed", "--dir", testTempDir, "test"},
+				input: strings.NewReader(""),
+			},
+			wantErrText: "Error: --remember can not be used with changed\n",
+		}, {
+			name: "changed: invalid key",
+			args: args{
+				args:  []string{"changed", "--dir", testTempDir, "../test"},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText:  "Error in runCli(changed): rememberStore invalid key: ../test\n",
+			wantExitCode: 2,
+		}, {
+			name: "ndjson: basic",
+			args: args{
+				args:  []string{"ndjson", "-d", `{"a": 0}`, "-t", `{"b": "none"}`},
+				input: strings.NewReader("{\"a\": 1, \"b\": \"test\"}\n\nnull\n"),
+			},
+			wantOutText: `{"a": 1, "b": "test"}
+{"a":0,"b":"none"}
+{"a":0,"b":"none"}
+`,
+		}, {
+			name: "ndjson: error",
+			args: args{
+				args:  []string{"ndjson"},
+				input: strings.NewReader("{\"a\": 1}\ninput data\n"),
+			},
+			wantOutText:  "{\"a\": 1}\n",
+			wantErrText:  "Error in runCli(ndjson): ndjsonCli.Run transforming the input: ndjsonReader line 2: invalid JSON record\n",
+			wantExitCode: 1,
+		}, {
+			name: "ndjson: global flags",
+			args: args{
+				args:  []string{"--remember", "test", "ndjson", "-d", `{"a": 0}`},
+				input: strings.NewReader("\n"),
+			},
+			wantErrText: "Error: --remember can not be used with ndjson\n",
+		}, {
+			name: "lines: default",
+			args: args{
+				args:  []string{"lines", "-d", "-"},
+				input: strings.NewReader("a\n\n \t\nb\n"),
+			},
+			wantOutText: "a\n-\n-\nb\n",
+		}, {
+			name: "lines: template null",
+			args: args{
+				args:  []string{"lines", "-z", "--template", "line{{.Line}}"},
+				input: strings.NewReader("a\x00\x00b\x00"),
+			},
+			wantOutText: "a\x00line2\x00b\x00",
+		}, {
+			name: "lines: conflicted",
+			args: args{
+				args:  []string{"lines", "-d", "-", "--template", "line{{.Line}}"},
+				input: strings.NewReader("a\n\nb\n"),
+			},
+			wantErrText:  "Error in runCli(lines): linesCli.Run the default value, the variable and the template are conflicted\n",
+			wantExitCode: 1,
+		}, {
+			name: "lines: global flags",
+			args: args{
+				args:  []string{"--lock", "test.lock", "lines", "-d", "-"},
+				input: strings.NewReader("a\n\nb\n"),
+			},
+			wantErrText: "Error: --lock can not be used with lines\n",
+		}, {
+			name: "string: escapes",
+			args: args{
+				args:  []string{"string", "-e", `standby\0data\t`},
+				input: strings.NewReader(""),
+			},
+			wantOutText: "standby\x00data\t\n",
+		}, {
+			name: "string: printf",
+			args: args{
+				args:  []string{"string", "--printf", `%s\0%s\0`, "standby", "data"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: "standby\x00data\x00",
+		}, {
+			name: "string: printf empty",
+			args: args{
+				args:  []string{"string", "--printf", "%s", ""},
+				input: strings.NewReader(""),
+			},
+			wantOutText: "",
+		}, {
+			name: "string: escapes stop empty",
+			args: args{
+				args:  []string{"string", "-e", `\cfoo`},
+				input: strings.NewReader(""),
+			},
+			wantOutText: "",
 		}, {
 			name: "string: leading newline",
 			args: args{
@@ -256,6 +704,37 @@ func Test_main(t *testing.T) {
 			},
 			// TODO: Args でのエラーが outSream になる原因調査(実際に動かすと stderr(2) へ出力されている).
 			wantOutText: `Error: requires at least 1 arg(s), only received 0
+`,
+			wantExitCode: 1,
+		}, {
+			name: "env: basic",
+			args: args{
+				args:  []string{"env", "TEI_TEST_ENV_CMD"},
+				input: os.Stdin,
+			},
+			wantOutText: `-n env data
+`,
+		}, {
+			name: "env: data",
+			args: args{
+				args:  []string{"env", "TEI_TEST_ENV_CMD"},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText: `input data`,
+		}, {
+			name: "env: -n",
+			args: args{
+				args:  []string{"env", "-n", "TEI_TEST_ENV_CMD"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `-n env data`,
+		}, {
+			name: "env: unset",
+			args: args{
+				args:  []string{"env", "--required", "TEI_TEST_ENV_CMD_UNSET"},
+				input: strings.NewReader(""),
+			},
+			wantErrText: `Error in runCli(env): Cli.Run reading the switched input: envCli.Run the variable is unset: TEI_TEST_ENV_CMD_UNSET
 `,
 			wantExitCode: 1,
 		}, {
@@ -297,6 +776,32 @@ func Test_main(t *testing.T) {
 			},
 			wantOutText: `
 `,
+		}, {
+			name: "run: env",
+			args: args{
+				args:  []string{"run", "--env", "TEST_VAR1=env1", "--env-file", testStandbyEnvFile(), "--clear-env", "--chdir", "/", testStandbyCmdEnv()},
+				input: strings.NewReader("\n"),
+			},
+			wantOutText: `standby cmd env: / newline 1 env1 file2 
+`,
+		}, {
+			name: "run: stdin",
+			args: args{
+				args:  []string{"run", "--stdin", "sniffed", testStandbyCmdCat(), "test"},
+				input: strings.NewReader("\n"),
+			},
+			wantOutText: `standby cmd cat: test
+
+`,
+		}, {
+			name: "run: invalid stdin",
+			args: args{
+				args:  []string{"run", "--stdin", "bogus", testStandbyCmdCat(), "test"},
+				input: strings.NewReader("input data"),
+			},
+			wantErrText: `Error in runCli(run): runCli.Run invalid stdin: bogus
+`,
+			wantExitCode: 1,
 		}, {
 			name: "run: error",
 			args: args{
@@ -335,6 +840,85 @@ func Test_main(t *testing.T) {
 			},
 			// TODO: Args でのエラーが outSream になる原因調査(実際に動かすと stderr(2) へ出力されている).
 			wantOutText: `Error: requires at least 1 arg(s), only received 0
+`,
+			wantExitCode: 1,
+		}, {
+			name: "ifdata: data",
+			args: args{
+				args:  []string{"ifdata", testStandbyCmdCat(), "test"},
+				input: strings.NewReader("input data"),
+			},
+			wantOutText: `standby cmd cat: test
+input data`,
+		}, {
+			name: "ifdata: no data",
+			args: args{
+				args:  []string{"ifdata", testStandbyCmdCat(), "test"},
+				input: strings.NewReader("\n"),
+			},
+		}, {
+			name: "ifdata: no data alternative",
+			args: args{
+				args:  []string{"ifdata", testStandbyCmdCat(), "test", "--", testStandbyCmd(), "alt"},
+				input: strings.NewReader(""),
+			},
+			wantOutText: `standby cmd: alt
+`,
+		}, {
+			name: "ifdata: -n",
+			args: args{
+				args:  []string{"ifdata", "-n", testStandbyCmd(), "test"},
+				input: os.Stdin,
+			},
+			wantOutText: `standby cmd: test
+`,
+		}, {
+			name: "ifdata: exit code",
+			args: args{
+				args:  []string{"ifdata", testStandbyCmdErr(), "test"},
+				input: strings.NewReader("input data"),
+			},
+			wantExitCode: 1,
+		}, {
+			name: "try: primary",
+			args: args{
+				args:  []string{"try", testStandbyCmd(), "primary", "--", testStandbyCmd(), "fallback"},
+				input: os.Stdin,
+			},
+			wantOutText: `standby cmd: primary
+`,
+		}, {
+			name: "try: fallback",
+			args: args{
+				args:  []string{"try", testStandbyCmdErr(), "primary", "--", testStandbyCmd(), "fallback"},
+				input: os.Stdin,
+			},
+			wantOutText: `standby cmd: fallback
+`,
+		}, {
+			name: "try: fallback file",
+			args: args{
+				args:  []string{"try", "--file", testStandbyFile(), testStandbyCmdErr()},
+				input: os.Stdin,
+			},
+			wantOutText: `file data
+`,
+		}, {
+			name: "try: primary not exist",
+			args: args{
+				args:  []string{"try", "./not_exist", "--", testStandbyCmd(), "fallback"},
+				input: os.Stdin,
+			},
+			wantOutText: `standby cmd: fallback
+`,
+			wantErrText: "Warning in tryCli: tryCli.Run start args([./not_exist]): fork/exec ./not_exist: no such file or directory\n",
+		}, {
+			name: "try: no fallback",
+			args: args{
+				args:  []string{"try", testStandbyCmdErr()},
+				input: os.Stdin,
+			},
+			wantErrText: `Error in runCli(try): tryCli.Run no fallback source
 `,
 			wantExitCode: 1,
 		}, {
@@ -388,6 +972,13 @@ func Test_main(t *testing.T) {
 			c.AddCommand(newFileCmd(builders))
 			c.AddCommand(newRunCmd(builders))
 			c.AddCommand(newStringCmd(builders))
+			c.AddCommand(newIfDataCmd(builders))
+			c.AddCommand(newTryCmd(builders))
+			c.AddCommand(newEnvCmd(builders))
+			c.AddCommand(newCheckCmd(builders))
+			c.AddCommand(newChangedCmd(builders))
+			c.AddCommand(newNDJSONCmd(builders))
+			c.AddCommand(newLinesCmd(builders))
 			c.AddCommand(newVersionCmd())
 
 			cmdExit = func(exitCode int) {
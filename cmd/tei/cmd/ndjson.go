@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newNDJSONCmd(builders globalBuildersFunc) *cobra.Command {
+	var defaultRecord string
+	var template string
+	// ndjsonCmd represents the ndjson command
+	cmd := &cobra.Command{
+		Use:          "ndjson [flags]",
+		SilenceUsage: true,
+		Short:        "Apply the defaults per record of the piped NDJSON",
+		Long: `ndjson applies the defaults per record of the piped NDJSON(newline-delimited JSON) while streaming.
+
+With --default, the blank lines and the null records are replaced with the record.
+With --template, the missing or empty(null, "", [], {}) fields of the object records are filled from the object.
+The records that are not changed are written as is.
+
+The global flags --json*, --csv*, --tsv, --remember* and --lock* are not applied(they are rejected).
+`,
+		Example: `  $ printf '{"a": 1}\n\nnull\n' | ` + cmdName + ` ndjson --default '{"a": 0}'
+  {"a": 1}
+  {"a":0}
+  {"a":0}
+  $ printf '{"a": 1}\n{"b": ""}\n' | ` + cmdName + ` ndjson --template '{"b": "none"}'
+  {"a":1,"b":"none"}
+  {"b":"none"}`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return rejectFlags(cmd, transformIgnoredFlags)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			_, cliBuilder := builders(nil, nil)
+			ndjsonCli := cliBuilder.
+				CmdName(cmd.Name()).
+				NDJSON(true).
+				NDJSONDefault(defaultRecord).
+				NDJSONTemplate(template).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), ndjsonCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringVarP(&defaultRecord, "default", "d", "", "the record that replaces the blank line and the null record")
+	flags.StringVarP(&template, "template", "t", "", "the object that fills the missing or empty fields of the object record")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newNDJSONCmd(builders))
+}
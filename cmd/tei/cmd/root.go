@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/cmd/tei/cli"
@@ -30,6 +32,16 @@ func runCli(ctx context.Context, cli cli.Cli) {
 	cmdExit(exitCode)
 }
 
+// splitArgsAtDash splits args at the first "--".
+func splitArgsAtDash(args []string) (before []string, after []string) {
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
 type globalBuildersFunc func(tei.Builder, cli.Builder) (tei.Builder, cli.Builder)
 
 var builders = func() globalBuildersFunc {
@@ -49,21 +61,100 @@ var builders = func() globalBuildersFunc {
 func newRootCmd(builders globalBuildersFunc) *cobra.Command {
 	var ignoreNewline bool
 	var passThrough bool
+	var inputFd int
+	var jsonMode bool
+	var jsonEmpty []string
+	var jsonLimit int64
+	var jsonPath string
+	var csvMode bool
+	var tsvMode bool
+	var csvFallback string
+	var csvLimit int64
+	var remember string
+	var rememberDir string
+	var rememberMaxSize int64
+	var rememberTTL time.Duration
+	var lock string
+	var lockMode string
+	var lockTimeout time.Duration
 	// rootCmd represents the base command when called without any subcommands
 	cmd := &cobra.Command{
 		Use:   "tei [flags] <exit_code>",
 		Short: cmdName + " switch the piped input to another one if no data from the piped input",
 		Long: cmdName + ` switch the piped input to another one if no data from the piped input,
 and simply use to just check no data from the piped input.
+
+See "` + cmdName + ` check" to check the piped input by the predicates(e.g. --json-valid),
+and "` + cmdName + ` changed" to check the piped input is changed from the last time.
+See "` + cmdName + ` ndjson" to apply the defaults per record of the piped NDJSON,
+and "` + cmdName + ` lines" to replace the empty lines of the piped input with the default value.
 `,
 		Example: `  $ ` + cmdName + ` 1                        # exit code = 0
   $ echo "" | ` + cmdName + ` 1              # exit code = 0
   $ echo "input data" | ` + cmdName + ` 1    # exit code = 1`,
 		Args: cobra.ExactArgs(1),
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			teiBuilder, _ := builders(nil, nil)
 			teiBuilder = teiBuilder.IgnoreLeadingNewline(ignoreNewline)
+			if jsonMode || jsonPath != "" {
+				if jsonLimit < tei.MinSniffLimit {
+					return fmt.Errorf("--json-limit must be %d or more: %d", tei.MinSniffLimit, jsonLimit)
+				}
+				teiBuilder = teiBuilder.JSON(true).JSONSniffLimit(jsonLimit).JSONPath(jsonPath)
+				if jsonEmpty != nil {
+					for _, v := range jsonEmpty {
+						if json.Valid([]byte(v)) == false {
+							// 不正な値は一致しないだけなので警告のみ.
+							fmt.Fprintf(cmd.ErrOrStderr(), "Warning: invalid JSON value in --json-empty: %s\n", v)
+						}
+					}
+					teiBuilder = teiBuilder.JSONEmptyValues(jsonEmpty)
+				}
+			}
+			if csvMode || tsvMode {
+				if csvLimit < tei.MinSniffLimit {
+					return fmt.Errorf("--csv-limit must be %d or more: %d", tei.MinSniffLimit, csvLimit)
+				}
+				switch tei.CSVFallback(csvFallback) {
+				case tei.CSVFallbackNone, tei.CSVFallbackCheckHeader, tei.CSVFallbackInputHeader:
+				default:
+					return fmt.Errorf("--csv-fallback must be check-header or input-header: %s", csvFallback)
+				}
+				teiBuilder = teiBuilder.CSV(true).
+					CSVSniffLimit(csvLimit).
+					CSVFallback(tei.CSVFallback(csvFallback))
+				if tsvMode {
+					teiBuilder = teiBuilder.CSVComma('\t')
+				}
+			}
+			switch lockMode {
+			case cli.LockWait, cli.LockNoWait:
+			default:
+				return fmt.Errorf("--lock-mode must be wait or no-wait: %s", lockMode)
+			}
 			builders(teiBuilder, nil)
+			if inputFd >= 0 {
+				// 開いていない fd の場合は読み込み時にエラーとなる.
+				cmd.SetIn(os.NewFile(uintptr(inputFd), "fd"+strconv.Itoa(inputFd)))
+			}
+			if remember != "" {
+				_, cliBuilder := builders(nil, nil)
+				cliBuilder = cliBuilder.
+					Remember(remember).
+					RememberDir(rememberDir).
+					RememberMaxSize(rememberMaxSize).
+					RememberTTL(rememberTTL)
+				builders(nil, cliBuilder)
+			}
+			if lock != "" {
+				_, cliBuilder := builders(nil, nil)
+				cliBuilder = cliBuilder.
+					Lock(lock).
+					LockMode(lockMode).
+					LockTimeout(lockTimeout)
+				builders(nil, cliBuilder)
+			}
+			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			exitCode, err := strconv.Atoi(args[0])
@@ -101,6 +192,22 @@ and simply use to just check no data from the piped input.
 	persistentFlags.SetInterspersed(false)
 
 	persistentFlags.BoolVarP(&ignoreNewline, "ignore-newline", "l", true, "ignore leading a newline while sniffing the input")
+	persistentFlags.IntVar(&inputFd, "input-fd", -1, "sniff the file descriptor instead of stdin")
+	persistentFlags.BoolVar(&jsonMode, "json", false, "treat the empty JSON value(null, [], {}, \"\") as no data")
+	persistentFlags.StringArrayVar(&jsonEmpty, "json-empty", nil, "the JSON value that is treated as no data instead of the defaults(with --json)")
+	persistentFlags.Int64Var(&jsonLimit, "json-limit", tei.DefaultJSONSniffLimit, "the max size(bytes, 3 or more) of the input that is sniffed as JSON(with --json)")
+	persistentFlags.StringVar(&jsonPath, "json-path", "", "treat the input as no data if the node selected by the path(e.g. .items) is missing or empty(implies --json)")
+	persistentFlags.BoolVar(&csvMode, "csv", false, "treat the CSV that has only a header as no data")
+	persistentFlags.BoolVar(&tsvMode, "tsv", false, "treat the TSV that has only a header as no data")
+	persistentFlags.StringVar(&csvFallback, "csv-fallback", "", "how the standby source is activated if the input has only a header: check-header|input-header(with --csv, --tsv)")
+	persistentFlags.Int64Var(&csvLimit, "csv-limit", tei.DefaultCSVSniffLimit, "the max size(bytes, 3 or more) of the input that is sniffed as CSV(with --csv, --tsv)")
+	persistentFlags.StringVar(&remember, "remember", "", "save the non-empty input with the key, and replay it if no data from the input")
+	persistentFlags.StringVar(&rememberDir, "remember-dir", "", "the directory that the input is saved in(default \"$XDG_CACHE_HOME/tei/remember\")")
+	persistentFlags.Int64Var(&rememberMaxSize, "remember-max-size", 0, "the max size(bytes) of the input that is saved(0 is unlimited)")
+	persistentFlags.DurationVar(&rememberTTL, "remember-ttl", 0, "the duration that the saved input is replayed(0 is no expiration)")
+	persistentFlags.StringVar(&lock, "lock", "", "lock the file exclusively while the standby source is running(can not be used with run --exec)")
+	persistentFlags.StringVar(&lockMode, "lock-mode", cli.LockWait, "how to wait for the lock: wait|no-wait")
+	persistentFlags.DurationVar(&lockTimeout, "lock-timeout", 0, "the timeout to wait for the lock(0 is no timeout)")
 
 	flags := cmd.Flags()
 	flags.SetInterspersed(false)
@@ -108,6 +215,30 @@ and simply use to just check no data from the piped input.
 	return cmd
 }
 
+// rememberLockFlags are the global flags that replay the input or lock the standby source,
+// they are not applied to the commands that do not switch to the standby source(e.g. check).
+var rememberLockFlags = []string{
+	"remember", "remember-dir", "remember-max-size", "remember-ttl",
+	"lock", "lock-mode", "lock-timeout",
+}
+
+// transformIgnoredFlags are the global flags that are not applied to the commands
+// that transform the input without switching(ndjson, lines).
+var transformIgnoredFlags = append([]string{
+	"json", "json-empty", "json-limit", "json-path",
+	"csv", "tsv", "csv-fallback", "csv-limit",
+}, rememberLockFlags...)
+
+// rejectFlags returns the error if any of the flags is set.
+func rejectFlags(cmd *cobra.Command, names []string) error {
+	for _, name := range names {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed {
+			return fmt.Errorf("--%s can not be used with %s", name, cmd.Name())
+		}
+	}
+	return nil
+}
+
 var rootCmd = newRootCmd(builders)
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -2,23 +2,54 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 func newRunCmd(builders globalBuildersFunc) *cobra.Command {
+	var chdir string
+	var env []string
+	var clearEnv bool
+	var envFile string
+	var execCmd bool
+	var cmdStdin string
+	var cache bool
+	var cacheTTL time.Duration
+	var cacheStale time.Duration
+	var cacheEnv []string
+	var cacheDir string
 	// runCmd represents the run command
 	cmd := &cobra.Command{
-		Use:                   "run <command> [command_args]...",
+		Use:                   "run [flags] <command> [command_args]...",
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		Short:                 "Switch the piped input to \"run command\"",
 		Long: `run switch the piped input to "run command [command_args]..."
 if no data from the piped input.
+
+The command can read the following environment variables.
+  TEI_REASON          the reason why the input was switched(no-data, newline, terminal, empty-json, header-only)
+  TEI_SNIFFED_BYTES   the number of bytes that were sniffed from the input
+
+With --exec, ` + cmdName + ` replaces itself with the command(exec(2)) instead of copying its output,
+so the command inherits stdin/stdout of ` + cmdName + ` directly(--stdin other than none can not be used).
+--lock can not be used with --exec, because the lock is not held by the command.
+
+With --stdin, the input that is switched(sniffed, the terminal is not read) or the input
+including the terminal(input) is connected to stdin of the command, for example to repair the partial input.
+
+With --cache, the output of the command is cached by argv, the working directory
+and the environment variables selected by --cache-env.
+Only the successful output is cached. The cached output is used until --cache-ttl,
+and with --cache-stale it is used for a while after --cache-ttl while refreshing it in the background.
+--cache can not be used with --exec or --stdin(other than none).
 `,
 		Example: `  $ ` + cmdName + ` run echo "standby data"                        # standby data
   $ echo "" | ` + cmdName + ` run echo "standby data"              # standby data
-  $ echo "input data" | ` + cmdName + ` run echo "standby data"    # input data`,
+  $ echo "input data" | ` + cmdName + ` run echo "standby data"    # input data
+  $ ` + cmdName + ` run --chdir /tmp --env FOO=bar sh -c 'echo "$(pwd) $FOO"'    # /tmp bar
+  $ ` + cmdName + ` run --cache --cache-ttl 10m --cache-stale 1h ./slow_query.sh`,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			teiBuilder, cliBuilder := builders(nil, nil)
@@ -26,6 +57,17 @@ if no data from the piped input.
 				CmdName(cmd.Name()).
 				TeiBuilder(teiBuilder).
 				CmdArgs(args).
+				Chdir(chdir).
+				Env(env).
+				ClearEnv(clearEnv).
+				EnvFile(envFile).
+				Exec(execCmd).
+				CmdStdin(cmdStdin).
+				Cache(cache).
+				CacheTTL(cacheTTL).
+				CacheStale(cacheStale).
+				CacheEnv(cacheEnv).
+				CacheDir(cacheDir).
 				InStream(cmd.InOrStdin()).
 				OutStream(cmd.OutOrStdout()).
 				ErrStream(cmd.ErrOrStderr()).
@@ -37,6 +79,17 @@ if no data from the piped input.
 	flags := cmd.Flags()
 	flags.SetInterspersed(false)
 
+	flags.StringVar(&chdir, "chdir", "", "change the working directory of the command")
+	flags.StringArrayVar(&env, "env", nil, "add the environment variable(KEY=VAL) to the command")
+	flags.BoolVar(&clearEnv, "clear-env", false, "do not inherit the environment")
+	flags.StringVar(&envFile, "env-file", "", "read the environment variables(KEY=VAL per line) from the file")
+	flags.BoolVar(&execCmd, "exec", false, "replace "+cmdName+" with the command if no data from the piped input")
+	flags.StringVar(&cmdStdin, "stdin", "", "connect to stdin of the command: none|sniffed|input (default none)")
+	flags.BoolVar(&cache, "cache", false, "cache the output of the command")
+	flags.DurationVar(&cacheTTL, "cache-ttl", 0, "the duration that the cached output is used(0 is no expiration)")
+	flags.DurationVar(&cacheStale, "cache-stale", 0, "the duration after --cache-ttl that the stale output is used while refreshing it")
+	flags.StringArrayVar(&cacheEnv, "cache-env", nil, "add the environment variable(KEY) to the cache key")
+	flags.StringVar(&cacheDir, "cache-dir", "", "the directory that the output is cached in(default \"$XDG_CACHE_HOME/tei/run\")")
 	return cmd
 }
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/cmd/tei/cli"
@@ -36,6 +37,80 @@ func Test_newRunCmd(t *testing.T) {
 				ErrStream(ioutil.Discard).
 				CmdArgs([]string{"foo", "--bar", "test"}).
 				Build(),
+		}, {
+			name: "env",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args: []string{
+					"--chdir", "/path/to", "--env", "FOO=foo", "--env", "BAR=bar",
+					"--clear-env", "--env-file", "/path/to/test.env", "foo", "--env", "test",
+				},
+			},
+			want: cli.NewBuilder().
+				CmdName("run").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				CmdArgs([]string{"foo", "--env", "test"}).
+				Chdir("/path/to").
+				Env([]string{"FOO=foo", "BAR=bar"}).
+				ClearEnv(true).
+				EnvFile("/path/to/test.env").
+				Build(),
+		}, {
+			name: "exec",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--exec", "foo", "--exec"},
+			},
+			want: cli.NewBuilder().
+				CmdName("run").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				CmdArgs([]string{"foo", "--exec"}).
+				Exec(true).
+				Build(),
+		}, {
+			name: "stdin",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--stdin", "input", "foo"},
+			},
+			want: cli.NewBuilder().
+				CmdName("run").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				CmdArgs([]string{"foo"}).
+				CmdStdin(cli.CmdStdinInput).
+				Build(),
+		}, {
+			name: "cache",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args: []string{
+					"--cache", "--cache-ttl", "10m", "--cache-stale", "1h",
+					"--cache-env", "FOO", "--cache-env", "BAR", "--cache-dir", "test_cache",
+					"foo",
+				},
+			},
+			want: cli.NewBuilder().
+				CmdName("run").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				CmdArgs([]string{"foo"}).
+				Cache(true).
+				CacheTTL(10 * time.Minute).
+				CacheStale(time.Hour).
+				CacheEnv([]string{"FOO", "BAR"}).
+				CacheDir("test_cache").
+				Build(),
 		}, {
 			name: "args=0",
 			args: args{
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hankei6km/go-tei"
 	"github.com/hankei6km/go-tei/cmd/tei/cli"
@@ -62,11 +63,331 @@ func (b *fakeCliBuilder) File(file string) cli.Builder {
 	return b
 }
 
+func (b *fakeCliBuilder) Files(files []string) cli.Builder {
+	b.b.Files(files)
+	return b
+}
+
+func (b *fakeCliBuilder) Missing(missing string) cli.Builder {
+	b.b.Missing(missing)
+	return b
+}
+
+func (b *fakeCliBuilder) Concat(concat bool) cli.Builder {
+	b.b.Concat(concat)
+	return b
+}
+
+func (b *fakeCliBuilder) Select(sel string) cli.Builder {
+	b.b.Select(sel)
+	return b
+}
+
+func (b *fakeCliBuilder) MaxAge(maxAge time.Duration) cli.Builder {
+	b.b.MaxAge(maxAge)
+	return b
+}
+
+func (b *fakeCliBuilder) Stale(stale string) cli.Builder {
+	b.b.Stale(stale)
+	return b
+}
+
 func (b *fakeCliBuilder) CmdArgs(cmdArgs []string) cli.Builder {
 	b.b.CmdArgs(cmdArgs)
 	return b
 }
 
+func (b *fakeCliBuilder) Chdir(chdir string) cli.Builder {
+	b.b.Chdir(chdir)
+	return b
+}
+
+func (b *fakeCliBuilder) Env(env []string) cli.Builder {
+	b.b.Env(env)
+	return b
+}
+
+func (b *fakeCliBuilder) ClearEnv(clearEnv bool) cli.Builder {
+	b.b.ClearEnv(clearEnv)
+	return b
+}
+
+func (b *fakeCliBuilder) EnvFile(envFile string) cli.Builder {
+	b.b.EnvFile(envFile)
+	return b
+}
+
+func (b *fakeCliBuilder) IfDataCmdArgs(ifDataCmdArgs []string) cli.Builder {
+	b.b.IfDataCmdArgs(ifDataCmdArgs)
+	return b
+}
+
+func (b *fakeCliBuilder) NoDataCmdArgs(noDataCmdArgs []string) cli.Builder {
+	b.b.NoDataCmdArgs(noDataCmdArgs)
+	return b
+}
+
+func (b *fakeCliBuilder) TryCmdArgs(tryCmdArgs []string) cli.Builder {
+	b.b.TryCmdArgs(tryCmdArgs)
+	return b
+}
+
+func (b *fakeCliBuilder) Exec(exec bool) cli.Builder {
+	b.b.Exec(exec)
+	return b
+}
+
+func (b *fakeCliBuilder) CmdStdin(cmdStdin string) cli.Builder {
+	b.b.CmdStdin(cmdStdin)
+	return b
+}
+
+func (b *fakeCliBuilder) Cache(cache bool) cli.Builder {
+	b.b.Cache(cache)
+	return b
+}
+
+func (b *fakeCliBuilder) CacheTTL(cacheTTL time.Duration) cli.Builder {
+	b.b.CacheTTL(cacheTTL)
+	return b
+}
+
+func (b *fakeCliBuilder) CacheStale(cacheStale time.Duration) cli.Builder {
+	b.b.CacheStale(cacheStale)
+	return b
+}
+
+func (b *fakeCliBuilder) CacheEnv(cacheEnv []string) cli.Builder {
+	b.b.CacheEnv(cacheEnv)
+	return b
+}
+
+func (b *fakeCliBuilder) CacheDir(cacheDir string) cli.Builder {
+	b.b.CacheDir(cacheDir)
+	return b
+}
+
+func (b *fakeCliBuilder) EnvVar(envVar string) cli.Builder {
+	b.b.EnvVar(envVar)
+	return b
+}
+
+func (b *fakeCliBuilder) EnvVarNewline(envVarNewline bool) cli.Builder {
+	b.b.EnvVarNewline(envVarNewline)
+	return b
+}
+
+func (b *fakeCliBuilder) EnvVarRequired(envVarRequired bool) cli.Builder {
+	b.b.EnvVarRequired(envVarRequired)
+	return b
+}
+
+func (b *fakeCliBuilder) Fd(fd int) cli.Builder {
+	b.b.Fd(fd)
+	return b
+}
+
+func (b *fakeCliBuilder) Socket(socket string) cli.Builder {
+	b.b.Socket(socket)
+	return b
+}
+
+func (b *fakeCliBuilder) SocketRequest(socketRequest string) cli.Builder {
+	b.b.SocketRequest(socketRequest)
+	return b
+}
+
+func (b *fakeCliBuilder) Fifo(fifo string) cli.Builder {
+	b.b.Fifo(fifo)
+	return b
+}
+
+func (b *fakeCliBuilder) FifoTimeout(fifoTimeout time.Duration) cli.Builder {
+	b.b.FifoTimeout(fifoTimeout)
+	return b
+}
+
+func (b *fakeCliBuilder) URL(url string) cli.Builder {
+	b.b.URL(url)
+	return b
+}
+
+func (b *fakeCliBuilder) URLHeaders(urlHeaders []string) cli.Builder {
+	b.b.URLHeaders(urlHeaders)
+	return b
+}
+
+func (b *fakeCliBuilder) URLTimeout(urlTimeout time.Duration) cli.Builder {
+	b.b.URLTimeout(urlTimeout)
+	return b
+}
+
+func (b *fakeCliBuilder) URLCacheDir(urlCacheDir string) cli.Builder {
+	b.b.URLCacheDir(urlCacheDir)
+	return b
+}
+
+func (b *fakeCliBuilder) Template(template string) cli.Builder {
+	b.b.Template(template)
+	return b
+}
+
+func (b *fakeCliBuilder) TemplateFile(templateFile string) cli.Builder {
+	b.b.TemplateFile(templateFile)
+	return b
+}
+
+func (b *fakeCliBuilder) Remember(remember string) cli.Builder {
+	b.b.Remember(remember)
+	return b
+}
+
+func (b *fakeCliBuilder) RememberDir(rememberDir string) cli.Builder {
+	b.b.RememberDir(rememberDir)
+	return b
+}
+
+func (b *fakeCliBuilder) RememberMaxSize(rememberMaxSize int64) cli.Builder {
+	b.b.RememberMaxSize(rememberMaxSize)
+	return b
+}
+
+func (b *fakeCliBuilder) RememberTTL(rememberTTL time.Duration) cli.Builder {
+	b.b.RememberTTL(rememberTTL)
+	return b
+}
+
+func (b *fakeCliBuilder) Lock(lock string) cli.Builder {
+	b.b.Lock(lock)
+	return b
+}
+
+func (b *fakeCliBuilder) LockMode(lockMode string) cli.Builder {
+	b.b.LockMode(lockMode)
+	return b
+}
+
+func (b *fakeCliBuilder) LockTimeout(lockTimeout time.Duration) cli.Builder {
+	b.b.LockTimeout(lockTimeout)
+	return b
+}
+
+func (b *fakeCliBuilder) Check(check bool) cli.Builder {
+	b.b.Check(check)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckMinBytes(checkMinBytes int) cli.Builder {
+	b.b.CheckMinBytes(checkMinBytes)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckMinLines(checkMinLines int) cli.Builder {
+	b.b.CheckMinLines(checkMinLines)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckMatch(checkMatch []string) cli.Builder {
+	b.b.CheckMatch(checkMatch)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckNoMatch(checkNoMatch []string) cli.Builder {
+	b.b.CheckNoMatch(checkNoMatch)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckJSONValid(checkJSONValid bool) cli.Builder {
+	b.b.CheckJSONValid(checkJSONValid)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckUTF8Valid(checkUTF8Valid bool) cli.Builder {
+	b.b.CheckUTF8Valid(checkUTF8Valid)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckPassThrough(checkPassThrough bool) cli.Builder {
+	b.b.CheckPassThrough(checkPassThrough)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckReport(checkReport string) cli.Builder {
+	b.b.CheckReport(checkReport)
+	return b
+}
+
+func (b *fakeCliBuilder) CheckReportFile(checkReportFile string) cli.Builder {
+	b.b.CheckReportFile(checkReportFile)
+	return b
+}
+
+func (b *fakeCliBuilder) Changed(changed bool) cli.Builder {
+	b.b.Changed(changed)
+	return b
+}
+
+func (b *fakeCliBuilder) ChangedKey(changedKey string) cli.Builder {
+	b.b.ChangedKey(changedKey)
+	return b
+}
+
+func (b *fakeCliBuilder) ChangedDir(changedDir string) cli.Builder {
+	b.b.ChangedDir(changedDir)
+	return b
+}
+
+func (b *fakeCliBuilder) ChangedUpdate(changedUpdate bool) cli.Builder {
+	b.b.ChangedUpdate(changedUpdate)
+	return b
+}
+
+func (b *fakeCliBuilder) ChangedPassThrough(changedPassThrough bool) cli.Builder {
+	b.b.ChangedPassThrough(changedPassThrough)
+	return b
+}
+
+func (b *fakeCliBuilder) NDJSON(ndjson bool) cli.Builder {
+	b.b.NDJSON(ndjson)
+	return b
+}
+
+func (b *fakeCliBuilder) NDJSONDefault(ndjsonDefault string) cli.Builder {
+	b.b.NDJSONDefault(ndjsonDefault)
+	return b
+}
+
+func (b *fakeCliBuilder) NDJSONTemplate(ndjsonTemplate string) cli.Builder {
+	b.b.NDJSONTemplate(ndjsonTemplate)
+	return b
+}
+
+func (b *fakeCliBuilder) Lines(lines bool) cli.Builder {
+	b.b.Lines(lines)
+	return b
+}
+
+func (b *fakeCliBuilder) LinesDefault(linesDefault string) cli.Builder {
+	b.b.LinesDefault(linesDefault)
+	return b
+}
+
+func (b *fakeCliBuilder) LinesDefaultEnv(linesDefaultEnv string) cli.Builder {
+	b.b.LinesDefaultEnv(linesDefaultEnv)
+	return b
+}
+
+func (b *fakeCliBuilder) LinesDefaultTemplate(linesDefaultTemplate string) cli.Builder {
+	b.b.LinesDefaultTemplate(linesDefaultTemplate)
+	return b
+}
+
+func (b *fakeCliBuilder) LinesNull(linesNull bool) cli.Builder {
+	b.b.LinesNull(linesNull)
+	return b
+}
+
 func (b *fakeCliBuilder) String(stringIntl string) cli.Builder {
 	b.b.String(stringIntl)
 	return b
@@ -177,6 +498,22 @@ func testStandbyFile() string {
 	return filepath.Join(cwd, "testdata", "standby.txt")
 }
 
+func testStandbyFile2() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby2.txt")
+}
+
+func testNewlineFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "newline.txt")
+}
+
 func testStandbyCmd() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -201,6 +538,30 @@ func testStandbyCmdErrOut() string {
 	return filepath.Join(cwd, "testdata", "standby_err_out.sh")
 }
 
+func testStandbyCmdCat() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby_cat.sh")
+}
+
+func testStandbyCmdEnv() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby_env.sh")
+}
+
+func testStandbyEnvFile() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(cwd, "testdata", "standby.env")
+}
+
 func TestMain(m *testing.M) {
 	saveCmdExit := cmdExit
 	defer func() {
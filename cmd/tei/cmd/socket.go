@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newSocketCmd(builders globalBuildersFunc) *cobra.Command {
+	var request string
+	// socketCmd represents the socket command
+	cmd := &cobra.Command{
+		Use:          "socket [flags] <path>",
+		SilenceUsage: true,
+		Short:        "Switch the piped input to the data from the Unix domain socket",
+		Long: `socket switch the piped input to the data from the Unix domain socket if no data from the piped input.
+
+If --request is specified, the line is sent to the socket before reading.
+`,
+		Example: `  $ ` + cmdName + ` socket /run/cached.sock                                # socat - UNIX-CONNECT:/run/cached.sock
+  $ echo "" | ` + cmdName + ` socket --request "GET snapshot" /run/cached.sock
+  $ echo "input data" | ` + cmdName + ` socket /run/cached.sock              # input data`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			socketCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Socket(args[0]).
+				SocketRequest(request).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), socketCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringVarP(&request, "request", "", "", "the line that is sent to the socket before reading")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newSocketCmd(builders))
+}
@@ -3,31 +3,201 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/hankei6km/go-tei/cmd/tei/cli"
 	"github.com/spf13/cobra"
 )
 
-var newLineString = fmt.Sprintln()
+// interpretEscapes interprets the backslash escapes as "echo -e".
+//
+// "\c" 以降は出力しない(stop が true になる).
+func interpretEscapes(s string) (ret string, stop bool) {
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'c':
+			return b.String(), true
+		case 'e':
+			b.WriteByte('\x1b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			// \0NNN(最大 3 桁の 8 進数).
+			v, n := 0, 0
+			for ; n < 3 && i+1+n < len(s) && s[i+1+n] >= '0' && s[i+1+n] <= '7'; n++ {
+				v = v*8 + int(s[i+1+n]-'0')
+			}
+			b.WriteByte(byte(v))
+			i += n
+		case 'x':
+			// \xHH(最大 2 桁の 16 進数).
+			v, n := 0, 0
+			for ; n < 2 && i+1+n < len(s); n++ {
+				d, err := strconv.ParseUint(s[i+1+n:i+2+n], 16, 8)
+				if err != nil {
+					break
+				}
+				v = v*16 + int(d)
+			}
+			if n == 0 {
+				b.WriteString("\\x")
+				continue
+			}
+			b.WriteByte(byte(v))
+			i += n
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), false
+}
+
+// printf formats the args as the shell's printf.
+//
+// 引数が余った場合は format を繰り返し適用する.
+// %b は引数の backslash escape を解釈する.
+func printf(format string, args []string) (string, error) {
+	format, stop := interpretEscapes(format)
+	b := &strings.Builder{}
+	for {
+		consumed := 0
+		next := func() (string, bool) {
+			if consumed >= len(args) {
+				return "", false
+			}
+			consumed++
+			return args[consumed-1], true
+		}
+		for i := 0; i < len(format); i++ {
+			if format[i] != '%' {
+				b.WriteByte(format[i])
+				continue
+			}
+			j := i + 1
+			for j < len(format) && strings.IndexByte("-+ #0123456789.", format[j]) >= 0 {
+				j++
+			}
+			if j >= len(format) {
+				return "", fmt.Errorf("printf missing verb: %s", format[i:])
+			}
+			spec := format[i:j]
+			verb := format[j]
+			i = j
+			switch verb {
+			case '%':
+				b.WriteByte('%')
+			case 's':
+				arg, _ := next()
+				fmt.Fprintf(b, spec+"s", arg)
+			case 'b':
+				arg, _ := next()
+				arg, stop := interpretEscapes(arg)
+				fmt.Fprintf(b, spec+"s", arg)
+				if stop {
+					return b.String(), nil
+				}
+			case 'c':
+				arg, _ := next()
+				if arg != "" {
+					b.WriteByte(arg[0])
+				}
+			case 'd', 'i', 'o', 'x', 'X':
+				arg, ok := next()
+				v := int64(0)
+				if ok && arg != "" {
+					var err error
+					if v, err = strconv.ParseInt(arg, 0, 64); err != nil {
+						return "", fmt.Errorf("printf invalid number: %s", arg)
+					}
+				}
+				if verb == 'i' {
+					verb = 'd'
+				}
+				fmt.Fprintf(b, spec+string(verb), v)
+			case 'e', 'E', 'f', 'F', 'g', 'G':
+				arg, ok := next()
+				v := float64(0)
+				if ok && arg != "" {
+					var err error
+					if v, err = strconv.ParseFloat(arg, 64); err != nil {
+						return "", fmt.Errorf("printf invalid number: %s", arg)
+					}
+				}
+				fmt.Fprintf(b, spec+string(verb), v)
+			default:
+				return "", fmt.Errorf("printf invalid verb: %s%c", spec, verb)
+			}
+		}
+		if stop || consumed == 0 || consumed >= len(args) {
+			break
+		}
+		args = args[consumed:]
+	}
+	return b.String(), nil
+}
 
 func newStringCmd(builders globalBuildersFunc) *cobra.Command {
 	var doNotNewLine bool
+	var escapes bool
+	var usePrintf bool
 	// stringCmd represents the string command
 	cmd := &cobra.Command{
 		Use:          "string [flags] <string>...",
 		SilenceUsage: true,
 		Short:        "Switch the piped input to \"echo string\"",
 		Long: `string switch the piped input to "echo string" if no data from the piped input.
+
+If --printf is specified, the first arg is used as the format of "printf FORMAT args...",
+the trailing newline is not added.
 `,
 		Example: `  $ ` + cmdName + ` string "standby data"                         # standby data
-  $ echo "" | ` + cmdName + ` string "standby data"               # standby data 
-  $ echo "input data" | ` + cmdName + ` string "staandby data"    # input data`,
+  $ echo "" | ` + cmdName + ` string "standby data"               # standby data
+  $ echo "input data" | ` + cmdName + ` string "staandby data"    # input data
+  $ ` + cmdName + ` string -e "key\tvalue"                         # key<TAB>value
+  $ ` + cmdName + ` string --printf "%s=%d\n" count 0              # count=0`,
 		Args: cobra.MinimumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			teiBuilder, cliBuilder := builders(nil, nil)
-			stringIntl := strings.Join(args, " ")
-			if doNotNewLine == false {
-				stringIntl = stringIntl + newLineString
+			var stringIntl string
+			if usePrintf {
+				s, err := printf(args[0], args[1:])
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error in formatting args: %s\n", err)
+					cmdExit(1)
+					return
+				}
+				stringIntl = s
+			} else {
+				stringIntl = strings.Join(args, " ")
+				stop := false
+				if escapes {
+					stringIntl, stop = interpretEscapes(stringIntl)
+				}
+				if doNotNewLine == false && stop == false {
+					stringIntl = stringIntl + cli.NewLineString
+				}
 			}
 			stringCli := cliBuilder.
 				CmdName(cmd.Name()).
@@ -45,6 +215,8 @@ func newStringCmd(builders globalBuildersFunc) *cobra.Command {
 	flags.SetInterspersed(false)
 
 	flags.BoolVarP(&doNotNewLine, "n", "n", false, "do not output the trailing newline")
+	flags.BoolVarP(&escapes, "e", "e", false, "enable interpretation of backslash escapes")
+	flags.BoolVarP(&usePrintf, "printf", "", false, "format args by the first arg as printf")
 	return cmd
 }
 
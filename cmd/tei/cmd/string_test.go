@@ -34,7 +34,7 @@ func Test_newStringCmd(t *testing.T) {
 				TeiBuilder(teiBuilder).
 				OutStream(ioutil.Discard).
 				ErrStream(ioutil.Discard).
-				String("standby" + newLineString).
+				String("standby" + cli.NewLineString).
 				Build(),
 		}, {
 			name: "join",
@@ -48,7 +48,7 @@ func Test_newStringCmd(t *testing.T) {
 				TeiBuilder(teiBuilder).
 				OutStream(ioutil.Discard).
 				ErrStream(ioutil.Discard).
-				String("standby string" + newLineString).
+				String("standby string" + cli.NewLineString).
 				Build(),
 		}, {
 			name: "no new line",
@@ -64,6 +64,83 @@ func Test_newStringCmd(t *testing.T) {
 				ErrStream(ioutil.Discard).
 				String("standby string").
 				Build(),
+		}, {
+			name: "escapes",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"-e", `key\tvalue\0`, `line1\nline2`},
+			},
+			want: cli.NewBuilder().
+				CmdName("string").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				String("key\tvalue\x00 line1\nline2" + cli.NewLineString).
+				Build(),
+		}, {
+			name: "escapes stop",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"-e", `standby\c`, "string"},
+			},
+			want: cli.NewBuilder().
+				CmdName("string").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				String("standby").
+				Build(),
+		}, {
+			name: "printf",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--printf", `%s=%03d\0`, "count", "7"},
+			},
+			want: cli.NewBuilder().
+				CmdName("string").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				String("count=007\x00").
+				Build(),
+		}, {
+			name: "escapes stop empty",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"-e", `\cfoo`},
+			},
+			want: cli.NewBuilder().
+				CmdName("string").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				String("").
+				Build(),
+		}, {
+			name: "printf empty",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--printf", "%s", ""},
+			},
+			want: cli.NewBuilder().
+				CmdName("string").
+				TeiBuilder(teiBuilder).
+				OutStream(ioutil.Discard).
+				ErrStream(ioutil.Discard).
+				String("").
+				Build(),
+		}, {
+			name: "printf invalid number",
+			args: args{
+				teiBuilder: teiBuilder,
+				cliBuilder: NewFakeCliBuilder(),
+				args:       []string{"--printf", "%d", "count"},
+			},
 		}, {
 			name: "args=0",
 			args: args{
@@ -99,3 +176,58 @@ func Test_newStringCmd(t *testing.T) {
 		})
 	}
 }
+
+func Test_interpretEscapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		wantRet  string
+		wantStop bool
+	}{
+		{name: "plain", s: "standby", wantRet: "standby"},
+		{name: "escapes", s: `\\\a\b\e\f\n\r\t\v`, wantRet: "\\\a\b\x1b\f\n\r\t\v"},
+		{name: "octal", s: `\0\0101\01234`, wantRet: "\x00A\x534"},
+		{name: "hex", s: `\x41\x4\x414\xg`, wantRet: "A\x04A4\\xg"},
+		{name: "unknown", s: `\q`, wantRet: `\q`},
+		{name: "trailing backslash", s: `standby\`, wantRet: `standby\`},
+		{name: "stop", s: `standby\cstring`, wantRet: "standby", wantStop: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRet, gotStop := interpretEscapes(tt.s)
+			assert.Equal(t, tt.wantRet, gotRet, "interpretEscapes() ret")
+			assert.Equal(t, tt.wantStop, gotStop, "interpretEscapes() stop")
+		})
+	}
+}
+
+func Test_printf(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "string", format: `%s\t%-4s|\n`, args: []string{"key", "val"}, want: "key\tval |\n"},
+		{name: "number", format: "%d %i %x %o %.2f %%", args: []string{"10", "0x10", "255", "8", "1.5"}, want: "10 16 ff 10 1.50 %"},
+		{name: "missing args", format: "%s|%d", want: "|0"},
+		{name: "repeat", format: `%s=%s\n`, args: []string{"a", "1", "b"}, want: "a=1\nb=\n"},
+		{name: "b", format: "%b|%c", args: []string{`a\tb`, "xyz"}, want: "a\tb|x"},
+		{name: "b stop", format: "%b|%s", args: []string{`a\cb`, "xyz"}, want: "a"},
+		{name: "nul", format: `\0%s\0`, args: []string{"data"}, want: "\x00data\x00"},
+		{name: "invalid number", format: "%d", args: []string{"x"}, wantErr: true},
+		{name: "invalid verb", format: "%q", args: []string{"x"}, wantErr: true},
+		{name: "missing verb", format: "%-", args: []string{"x"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := printf(tt.format, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("printf() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got, "printf()")
+		})
+	}
+}
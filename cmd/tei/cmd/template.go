@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hankei6km/go-tei/cmd/tei/cli"
+	"github.com/spf13/cobra"
+)
+
+func newTemplateCmd(builders globalBuildersFunc) *cobra.Command {
+	var doNotNewLine bool
+	var file string
+	// templateCmd represents the template command
+	cmd := &cobra.Command{
+		Use:          "template [flags] <template>...",
+		SilenceUsage: true,
+		Short:        "Switch the piped input to the rendered text/template",
+		Long: `template switch the piped input to the rendered text/template if no data from the piped input.
+
+The data of the template:
+  .Env       the environment variables(map)
+  .Time      the time when the template is rendered
+  .Hostname  the host name
+  .Reason    the reason why the input is switched(no-data, newline, terminal, empty-json, header-only)
+  .Sniffed   the sniffed bytes from the input
+
+The functions of the template:
+  json       encode the value as JSON
+  default    "default DEFAULT VALUE" returns DEFAULT if VALUE is empty
+  now        the current time
+`,
+		Example: `  $ ` + cmdName + ` template '{"host": {{ json .Hostname }}, "at": "{{ .Time.Format "2006-01-02T15:04:05Z07:00" }}"}'
+  $ echo "" | ` + cmdName + ` template '{{ default "guest" .Env.USER }}'     # $USER or guest
+  $ echo "input data" | ` + cmdName + ` template --file default.tmpl       # input data`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if file != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("accepts no args with --file, received %d", len(args))
+				}
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			template := ""
+			if file == "" {
+				template = strings.Join(args, " ")
+				if doNotNewLine == false {
+					template = template + cli.NewLineString
+				}
+			}
+			templateCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				Template(template).
+				TemplateFile(file).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), templateCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.BoolVarP(&doNotNewLine, "n", "n", false, "do not output the trailing newline")
+	flags.StringVarP(&file, "file", "f", "", "the file that has the template(the newline is not added)")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTemplateCmd(builders))
+}
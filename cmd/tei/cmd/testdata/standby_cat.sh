@@ -0,0 +1,4 @@
+#!/bin/sh
+
+echo "standby cmd cat: ${@}"
+cat
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+func newTryCmd(builders globalBuildersFunc) *cobra.Command {
+	var file string
+	var stringIntl string
+	// tryCmd represents the try command
+	cmd := &cobra.Command{
+		Use:                   "try [flags] <primary> [primary_args]... [-- <fallback> [fallback_args]...]",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Short:                 "Switch the output of \"primary\" to \"fallback\"",
+		Long: `try run "primary [primary_args]..." and switch its output to "fallback [fallback_args]..."
+(or the file, the string) if no data from the primary or the primary exits non-zero(or can not be started).
+
+The output of the primary is streamed without buffering once the data is detected,
+so the exit code of the primary is returned instead of switching if it exits non-zero after that.
+`,
+		Example: `  $ ` + cmdName + ` try echo "primary data" -- echo "fallback data"             # primary data
+  $ ` + cmdName + ` try echo "" -- echo "fallback data"                         # fallback data
+  $ ` + cmdName + ` try false -- echo "fallback data"                           # fallback data
+  $ ` + cmdName + ` try --file fallback.txt false                               # cat fallback.txt`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			tryCmdArgs, cmdArgs := splitArgsAtDash(args)
+			cliBuilder = cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				TryCmdArgs(tryCmdArgs).
+				CmdArgs(cmdArgs).
+				File(file)
+			if cmd.Flags().Changed("string") {
+				// --string "" も fallback として扱う.
+				cliBuilder = cliBuilder.String(stringIntl)
+			}
+			tryCli := cliBuilder.
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), tryCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringVar(&file, "file", "", "switch to the file instead of the fallback command")
+	flags.StringVar(&stringIntl, "string", "", "switch to the string instead of the fallback command")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTryCmd(builders))
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newURLCmd(builders globalBuildersFunc) *cobra.Command {
+	var headers []string
+	var timeout time.Duration
+	var cacheDir string
+	// urlCmd represents the url command
+	cmd := &cobra.Command{
+		Use:          "url [flags] <url>",
+		SilenceUsage: true,
+		Short:        "Switch the piped input to the response of GET <url>",
+		Long: `url switch the piped input to the response of GET <url> if no data from the piped input.
+
+The non-2xx status is an error.
+If --cache-dir is specified, the response is cached by the URL and the request headers(-H)
+and revalidated by ETag/Last-Modified,
+and the cached response is used while the request is failed(e.g. offline).
+`,
+		Example: `  $ ` + cmdName + ` url http://localhost:8080/default.json
+  $ echo "" | ` + cmdName + ` url -H "Authorization: Bearer $TOKEN" --timeout 5s http://localhost:8080/default.json
+  $ echo "input data" | ` + cmdName + ` url --cache-dir ~/.cache/tei http://localhost:8080/default.json   # input data`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			teiBuilder, cliBuilder := builders(nil, nil)
+			urlCli := cliBuilder.
+				CmdName(cmd.Name()).
+				TeiBuilder(teiBuilder).
+				URL(args[0]).
+				URLHeaders(headers).
+				URLTimeout(timeout).
+				URLCacheDir(cacheDir).
+				InStream(cmd.InOrStdin()).
+				OutStream(cmd.OutOrStdout()).
+				ErrStream(cmd.ErrOrStderr()).
+				Build()
+			runCli(context.Background(), urlCli)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.SetInterspersed(false)
+
+	flags.StringArrayVarP(&headers, "header", "H", nil, "the request header(\"Key: Value\")")
+	flags.DurationVarP(&timeout, "timeout", "", 0, "the timeout of the request(0 is no timeout)")
+	flags.StringVarP(&cacheDir, "cache-dir", "", "", "the directory that the response is cached in")
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newURLCmd(builders))
+}
@@ -0,0 +1,185 @@
+package tei
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// CSVFallback is how the standby source is activated if the input has only a header.
+type CSVFallback string
+
+const (
+	// CSVFallbackNone is that the standby source is activated as is.
+	CSVFallbackNone CSVFallback = ""
+	// CSVFallbackCheckHeader is that the header of the standby source must match the header of the input.
+	CSVFallbackCheckHeader CSVFallback = "check-header"
+	// CSVFallbackInputHeader is that the header of the input is followed by the data rows of the standby source.
+	CSVFallbackInputHeader CSVFallback = "input-header"
+)
+
+// DefaultCSVSniffLimit is the max size of the input that is sniffed as CSV by default.
+const DefaultCSVSniffLimit int64 = 4096
+
+// readCSVRecord reads the raw bytes of the record(includes the newline).
+//
+// 引用符の数が奇数の間は改行をフィールド内とみなして読み込みを続ける.
+func readCSVRecord(br *bufio.Reader) ([]byte, error) {
+	rec := []byte{}
+	quotes := 0
+	for {
+		line, err := br.ReadBytes('\n')
+		rec = append(rec, line...)
+		quotes += bytes.Count(line, []byte{'"'})
+		if err != nil || quotes%2 == 0 {
+			return rec, err
+		}
+	}
+}
+
+// readCSVHeader reads the first record that is not blank.
+func readCSVHeader(br *bufio.Reader) ([]byte, error) {
+	for {
+		rec, err := readCSVRecord(br)
+		if isBlankCSVRecord(rec) == false {
+			return rec, err
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isBlankCSVRecord(rec []byte) bool {
+	return len(bytes.TrimRight(rec, "\r\n")) == 0
+}
+
+// parseCSVRecord parses the raw bytes of the record to the fields.
+func parseCSVRecord(rec []byte, comma rune) ([]string, error) {
+	r := csv.NewReader(bytes.NewReader(rec))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.Read()
+}
+
+// sniffCSV sniffs the input up to the limit, and switches if the input has only a header.
+//
+// 2 件目のレコードを読み込んだ時点でデータありと判定する(残りの入力は読み込まない).
+// 空行は無視する. limit までに判定できない場合はデータありとして扱う.
+func (t *baseTei) sniffCSV(buf *bytes.Buffer, input io.Reader) io.Reader {
+	tee := &limitedTeeReader{
+		r:     io.MultiReader(buf, input),
+		buf:   bytes.NewBuffer([]byte{}),
+		limit: t.csvSniffLimit,
+	}
+	br := bufio.NewReader(tee)
+	header, err := readCSVHeader(br)
+	if err == nil {
+		var rec []byte
+		rec, err = readCSVHeader(br)
+		if len(rec) > 0 {
+			// データ行がある(tee が読み込んでいない部分は tee の入力から続ける).
+			return io.MultiReader(tee.buf, tee.r)
+		}
+	}
+	switch {
+	case tee.err != nil:
+		return ErrReader(errors.Wrapf(tee.err, "baseTei.Switch sniffing the input as CSV"))
+	case err == io.EOF:
+		return t.csvFallbackReader(header, t.switchTo(ReasonHeaderOnly, tee.buf.Bytes()))
+	}
+	// limit を超えた場合.
+	return io.MultiReader(tee.buf, tee.r)
+}
+
+// csvFallbackReader returns the standby source that is handled by the fallback mode.
+func (t *baseTei) csvFallbackReader(header []byte, standby io.Reader) io.Reader {
+	if standby == nil {
+		return nil
+	}
+	switch t.csvFallback {
+	case CSVFallbackNone:
+		return standby
+	case CSVFallbackCheckHeader, CSVFallbackInputHeader:
+		return &csvStandbyReader{
+			header:   header,
+			standby:  standby,
+			comma:    t.csvComma,
+			fallback: t.csvFallback,
+		}
+	}
+	return ErrReader(fmt.Errorf("baseTei.Switch invalid CSV fallback: %s", t.csvFallback))
+}
+
+// validCSVFallback returns true if the fallback is the one of CSVFallback*.
+func validCSVFallback(fallback CSVFallback) bool {
+	switch fallback {
+	case CSVFallbackNone, CSVFallbackCheckHeader, CSVFallbackInputHeader:
+		return true
+	}
+	return false
+}
+
+// csvStandbyReader replaces or checks the header of the standby source.
+//
+// standby の header は最初の Read で読み込む.
+type csvStandbyReader struct {
+	header   []byte
+	standby  io.Reader
+	comma    rune
+	fallback CSVFallback
+	r        io.Reader
+}
+
+func (c *csvStandbyReader) init() error {
+	br := bufio.NewReader(c.standby)
+	standbyHeader, err := readCSVHeader(br)
+	if err != nil && err != io.EOF {
+		return errors.Wrapf(err, "csvStandbyReader reading the header of the standby source")
+	}
+
+	if c.fallback == CSVFallbackInputHeader {
+		header := c.header
+		if len(header) == 0 {
+			// 入力に header がない(空行のみ)場合は standby をそのまま使う.
+			header = standbyHeader
+		}
+		if len(header) > 0 && header[len(header)-1] != '\n' {
+			header = append(header, '\n')
+		}
+		c.r = io.MultiReader(bytes.NewReader(header), br)
+		return nil
+	}
+
+	if len(standbyHeader) == 0 {
+		return fmt.Errorf("csvStandbyReader the standby source has no header")
+	}
+	want, err := parseCSVRecord(c.header, c.comma)
+	if err != nil {
+		return errors.Wrapf(err, "csvStandbyReader parsing the header of the input")
+	}
+	got, err := parseCSVRecord(standbyHeader, c.comma)
+	if err != nil {
+		return errors.Wrapf(err, "csvStandbyReader parsing the header of the standby source")
+	}
+	if reflect.DeepEqual(want, got) == false {
+		return fmt.Errorf("csvStandbyReader the header of the standby source does not match: %q, want %q", got, want)
+	}
+	c.r = io.MultiReader(bytes.NewReader(standbyHeader), br)
+	return nil
+}
+
+func (c *csvStandbyReader) Read(p []byte) (int, error) {
+	if c.r == nil {
+		if err := c.init(); err != nil {
+			c.r = ErrReader(err)
+		}
+	}
+	return c.r.Read(p)
+}
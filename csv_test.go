@@ -0,0 +1,225 @@
+package tei
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_baseTei_CSV(t *testing.T) {
+	standbyFunc := func(s string) StandbyFunc {
+		return func() io.Reader {
+			return bytes.NewBufferString(s)
+		}
+	}
+	tests := []struct {
+		name    string
+		builder Builder
+		input   io.Reader
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "header only",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n"),
+			want:  []byte("standby data"),
+		}, {
+			name: "header only without newline",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name"),
+			want:  []byte("standby data"),
+		}, {
+			name: "header only with blank lines",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("\r\nid,name\r\n\r\n"),
+			want:  []byte("standby data"),
+		}, {
+			name: "header only quoted newline",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,\"the\nname\"\n"),
+			want:  []byte("standby data"),
+		}, {
+			name: "data",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n\n1,input data\n"),
+			want:  []byte("id,name\n\n1,input data\n"),
+		}, {
+			name: "data without newline",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n1,input data"),
+			want:  []byte("id,name\n1,input data"),
+		}, {
+			name: "csv=false",
+			builder: NewBuilder().
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n"),
+			want:  []byte("id,name\n"),
+		}, {
+			name: "no data",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString(""),
+			want:  []byte("standby data"),
+		}, {
+			name: "limit",
+			builder: NewBuilder().CSV(true).CSVSniffLimit(8).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n"),
+			want:  []byte("standby data"),
+		}, {
+			name: "limit exceeded",
+			builder: NewBuilder().CSV(true).CSVSniffLimit(7).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("id,name\n"),
+			want:  []byte("id,name\n"),
+		}, {
+			name: "limit min",
+			builder: NewBuilder().CSV(true).CSVSniffLimit(MinSniffLimit).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("a,b\n1,2\n"),
+			want:  []byte("a,b\n1,2\n"),
+		}, {
+			name: "limit min exceeded by a byte",
+			builder: NewBuilder().CSV(true).CSVSniffLimit(MinSniffLimit + 1).
+				Standby(standbyFunc("standby data")),
+			input: bytes.NewBufferString("a,b\n1,2\n"),
+			want:  []byte("a,b\n1,2\n"),
+		}, {
+			name: "limit less than min",
+			builder: NewBuilder().CSV(true).CSVSniffLimit(MinSniffLimit - 1).
+				Standby(standbyFunc("standby data")),
+			input:   bytes.NewBufferString("a,b\n1,2\n"),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "check header",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackCheckHeader).
+				Standby(standbyFunc("\"id\",name\n1,standby data\n")),
+			input: bytes.NewBufferString("id,name\n"),
+			want:  []byte("\"id\",name\n1,standby data\n"),
+		}, {
+			name: "check header tsv",
+			builder: NewBuilder().CSV(true).CSVComma('\t').CSVFallback(CSVFallbackCheckHeader).
+				Standby(standbyFunc("id\tname\n1\tstandby data\n")),
+			input: bytes.NewBufferString("id\tname\n"),
+			want:  []byte("id\tname\n1\tstandby data\n"),
+		}, {
+			name: "check header mismatch",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackCheckHeader).
+				Standby(standbyFunc("id,title\n1,standby data\n")),
+			input:   bytes.NewBufferString("id,name\n"),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "check header no header",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackCheckHeader).
+				Standby(standbyFunc("")),
+			input:   bytes.NewBufferString("id,name\n"),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "input header",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackInputHeader).
+				Standby(standbyFunc("ID,NAME\n1,standby data\n2,standby data\n")),
+			input: bytes.NewBufferString("\nid,name"),
+			want:  []byte("id,name\n1,standby data\n2,standby data\n"),
+		}, {
+			name: "input header empty standby",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackInputHeader).
+				Standby(standbyFunc("")),
+			input: bytes.NewBufferString("id,name\r\n"),
+			want:  []byte("id,name\r\n"),
+		}, {
+			name: "input header blank input",
+			builder: NewBuilder().CSV(true).CSVFallback(CSVFallbackInputHeader).
+				Standby(standbyFunc("ID,NAME\n1,standby data\n")),
+			input: bytes.NewBufferString("\n\n\n"),
+			want:  []byte("ID,NAME\n1,standby data\n"),
+		}, {
+			name: "invalid fallback",
+			builder: NewBuilder().CSV(true).CSVFallback("test").
+				Standby(standbyFunc("standby data")),
+			input:   bytes.NewBufferString("id,name\n"),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "invalid fallback with data",
+			builder: NewBuilder().CSV(true).CSVFallback("test").
+				Standby(standbyFunc("standby data")),
+			input:   bytes.NewBufferString("id,name\n1,input data\n"),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "error",
+			builder: NewBuilder().CSV(true).
+				Standby(standbyFunc("standby data")),
+			input: io.MultiReader(
+				bytes.NewBufferString("id,name\n"),
+				ErrReader(errors.New("test error")),
+			),
+			want:    []byte{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.builder.Build().Switch(tt.input)
+			buf := bytes.NewBuffer([]byte{})
+			_, err := io.Copy(buf, r)
+			got := buf.Bytes()
+			assert.Equal(t, tt.want, got, "baseTei.Switch() read", string(got))
+			if assert.Equal(t, tt.wantErr, (err != nil), "baseTei.Reder() error in read") == false {
+				log.Println(err)
+			}
+		})
+	}
+}
+
+func Test_baseTei_CSV_OnSwitch(t *testing.T) {
+	var gotReason Reason
+	var gotSniffed []byte
+	r := NewBuilder().CSV(true).
+		Standby(func() io.Reader { return nil }).
+		OnSwitch(func(reason Reason, sniffed []byte) {
+			gotReason = reason
+			gotSniffed = sniffed
+		}).
+		Build().Switch(bytes.NewBufferString("id,name\n\n"))
+	assert.Nil(t, r, "baseTei.Switch()")
+	assert.Equal(t, ReasonHeaderOnly, gotReason, "baseTei.Switch() reason")
+	assert.Equal(t, []byte("id,name\n\n"), gotSniffed, "baseTei.Switch() sniffed")
+}
+
+func Test_baseTei_CSV_streaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	switched := make(chan struct{})
+	go func() {
+		pw.Write([]byte("id,name\n1,input data\n"))
+		// Switch が返るまでは残りを書き込まない(全体を読み込もうとするとデッドロックする).
+		<-switched
+		pw.Write([]byte("2,input data\n"))
+		pw.Close()
+	}()
+
+	r := NewBuilder().CSV(true).
+		Standby(func() io.Reader {
+			return bytes.NewBufferString("standby data")
+		}).
+		Build().Switch(pr)
+	close(switched)
+	b, err := ioutil.ReadAll(r)
+	assert.Nil(t, err, "baseTei.Switch() error in read")
+	assert.Equal(t, "id,name\n1,input data\n2,input data\n", string(b), "baseTei.Switch() read")
+}
@@ -55,3 +55,61 @@ func ExampleBuilder_IgnoreLeadingNewline() {
 	// data
 	// data
 }
+
+func ExampleBuilder_JSON() {
+	output := func(builder tei.Builder, r io.Reader) {
+		tei := builder.Standby(func() io.Reader {
+			return nil
+		}).Build()
+
+		if tei.Switch(r) == nil {
+			fmt.Println("no-data")
+			return
+		}
+		fmt.Println("data")
+
+	}
+	output(tei.NewBuilder().JSON(true), strings.NewReader("[]\n"))
+	output(tei.NewBuilder().JSON(true), strings.NewReader(`{"items": []}`))
+	output(tei.NewBuilder().JSON(true).JSONEmptyValues([]string{`{"items": []}`}), strings.NewReader(`{"items": []}`))
+
+	// Output:
+	// no-data
+	// data
+	// no-data
+}
+
+func ExampleNDJSONBuilder() {
+	transformer := tei.NewNDJSONBuilder().
+		DefaultRecord(`{"name": null}`).
+		Template(`{"name": "unknown", "count": 0}`).
+		Build()
+
+	io.Copy(os.Stdout, transformer.Transform(strings.NewReader(strings.Join([]string{
+		`{"name": "test", "count": 1}`,
+		`null`,
+		`{"name": ""}`,
+		``,
+	}, "\n"))))
+
+	// Output:
+	// {"name": "test", "count": 1}
+	// {"name":"unknown","count":0}
+	// {"name":"unknown","count":0}
+}
+
+func ExampleLineBuilder() {
+	transformer := tei.NewLineBuilder().
+		DefaultFunc(func(lineNum int) (string, error) {
+			return fmt.Sprintf("(empty line %d)", lineNum), nil
+		}).
+		Build()
+
+	io.Copy(os.Stdout, transformer.Transform(strings.NewReader("a\n\nb\n \n")))
+
+	// Output:
+	// a
+	// (empty line 2)
+	// b
+	// (empty line 4)
+}
@@ -3,6 +3,8 @@
 package errors
 
 import (
+	"reflect"
+
 	"github.com/pkg/errors"
 )
 
@@ -10,3 +12,21 @@ import (
 func Wrapf(err error, format string, a ...interface{}) error {
 	return errors.Wrapf(err, format, a...)
 }
+
+// As finds the first error in err's chain(github.com/pkg/errors/Cause) that matches target.
+func As(err error, target interface{}) bool {
+	val := reflect.ValueOf(target)
+	typ := val.Type().Elem()
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(typ) {
+			val.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
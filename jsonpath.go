@@ -0,0 +1,196 @@
+package tei
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// jsonPathStep is the step of the path(the key of the object or the index of the array).
+type jsonPathStep struct {
+	key   string
+	index int
+	isKey bool
+}
+
+func isJSONPathKeyChar(c byte) bool {
+	return c == '_' || c == '-' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// parseJSONPath parses the jq-lite path(e.g. ".", ".items", ".data.items[0]", `.["a key"]`).
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	if len(path) == 0 || path[0] != '.' {
+		return nil, fmt.Errorf("parseJSONPath: the path must start with '.': %s", path)
+	}
+	steps := []jsonPathStep{}
+	rest := path[1:]
+	atStart := true
+	for len(rest) > 0 {
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("parseJSONPath: unterminated '[': %s", path)
+			}
+			inner := rest[1:end]
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("parseJSONPath: invalid key %s: %s", inner, path)
+				}
+				steps = append(steps, jsonPathStep{key: key, isKey: true})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("parseJSONPath: invalid index %s: %s", inner, path)
+				}
+				steps = append(steps, jsonPathStep{index: index})
+			}
+			rest = rest[end+1:]
+			atStart = false
+			continue
+		}
+
+		// 先頭以外のキーは "." で区切る(".a.[0]" のように "[" が続くこともできる).
+		if rest[0] == '.' {
+			rest = rest[1:]
+			if len(rest) > 0 && rest[0] == '[' {
+				continue
+			}
+		} else if atStart == false {
+			return nil, fmt.Errorf("parseJSONPath: invalid character %q: %s", rest[0], path)
+		}
+		n := 0
+		for n < len(rest) && isJSONPathKeyChar(rest[n]) {
+			n++
+		}
+		if n == 0 {
+			return nil, fmt.Errorf("parseJSONPath: empty key: %s", path)
+		}
+		steps = append(steps, jsonPathStep{key: rest[:n], isKey: true})
+		rest = rest[n:]
+		atStart = false
+	}
+	return steps, nil
+}
+
+// shallowEmptyValues returns true if the empty values are only the scalar values or the empty containers.
+//
+// この場合、要素を持つ配列やオブジェクトは(全体を decode しなくても)空でないと判定できる.
+func shallowEmptyValues(emptyValues []string) bool {
+	for _, e := range emptyValues {
+		v, err := canonicalJSON([]byte(e))
+		if err == nil && len(v) > 2 && (v[0] == '[' || v[0] == '{') {
+			return false
+		}
+	}
+	return true
+}
+
+// selectJSON selects the node by steps from the decoder, and checks the node is empty.
+//
+// 対象のノードを判定した時点で読み込みを止める(残りの部分は読み込まない).
+// shallow の場合、要素を持つ配列やオブジェクトは最初の要素を読み込む前に空でないと判定する.
+func selectJSON(dec *json.Decoder, steps []jsonPathStep, emptyValues []string, shallow bool) (found bool, empty bool, err error) {
+	if len(steps) == 0 {
+		var node json.RawMessage
+		if shallow == false {
+			if err := dec.Decode(&node); err != nil {
+				return false, false, err
+			}
+			return true, isEmptyJSON(node, emptyValues), nil
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return false, false, err
+		}
+		switch tok {
+		case json.Delim('['), json.Delim('{'):
+			if dec.More() {
+				return true, false, nil
+			}
+			if _, err := dec.Token(); err != nil {
+				return false, false, err
+			}
+			node = json.RawMessage(`{}`)
+			if tok == json.Delim('[') {
+				node = json.RawMessage(`[]`)
+			}
+		default:
+			if node, err = json.Marshal(tok); err != nil {
+				return false, false, err
+			}
+		}
+		return true, isEmptyJSON(node, emptyValues), nil
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return false, false, err
+	}
+	var skip json.RawMessage
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return false, false, err
+			}
+			if key, _ := keyTok.(string); steps[0].isKey && key == steps[0].key {
+				return selectJSON(dec, steps[1:], emptyValues, shallow)
+			}
+			if err := dec.Decode(&skip); err != nil {
+				return false, false, err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if steps[0].isKey == false && i == steps[0].index {
+				return selectJSON(dec, steps[1:], emptyValues, shallow)
+			}
+			if err := dec.Decode(&skip); err != nil {
+				return false, false, err
+			}
+		}
+	default:
+		// スカラー値に子要素は存在しない.
+		return false, false, nil
+	}
+	// 閉じ括弧.
+	if _, err := dec.Token(); err != nil {
+		return false, false, err
+	}
+	return false, false, nil
+}
+
+// sniffJSONPath sniffs the input up to the limit, and switches if the node selected by the path is missing or empty.
+//
+// 入力はストリームとして decode するので、対象のノードが空でないと判定できた時点で残りの入力を読まずに出力を開始できる.
+// limit までに判定できない場合や JSON として不正な場合はデータありとして扱う.
+func (t *baseTei) sniffJSONPath(buf *bytes.Buffer, input io.Reader) io.Reader {
+	steps, err := parseJSONPath(t.jsonPath)
+	if err != nil {
+		return ErrReader(errors.Wrapf(err, "baseTei.Switch parsing the JSON path"))
+	}
+	tee := &limitedTeeReader{
+		r:     io.MultiReader(buf, input),
+		buf:   bytes.NewBuffer([]byte{}),
+		limit: t.jsonSniffLimit,
+	}
+	found, empty, err := selectJSON(json.NewDecoder(tee), steps, t.jsonEmptyValues, shallowEmptyValues(t.jsonEmptyValues))
+	switch {
+	case tee.err != nil:
+		return ErrReader(errors.Wrapf(tee.err, "baseTei.Switch sniffing the input as JSON"))
+	case err != nil:
+		// 不正な JSON や limit を超えた場合.
+	case found == false || empty:
+		return t.switchTo(ReasonEmptyJSON, tee.buf.Bytes())
+	}
+	// tee が読み込んでいない部分(sniff 済みの内容も含む)は tee の入力から続ける.
+	return io.MultiReader(tee.buf, tee.r)
+}
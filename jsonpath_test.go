@@ -0,0 +1,261 @@
+package tei
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseJSONPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []jsonPathStep
+		wantErr bool
+	}{
+		{
+			name: "root",
+			path: ".",
+			want: []jsonPathStep{},
+		}, {
+			name: "key",
+			path: ".items",
+			want: []jsonPathStep{{key: "items", isKey: true}},
+		}, {
+			name: "nested",
+			path: ".data.items_2[0]",
+			want: []jsonPathStep{{key: "data", isKey: true}, {key: "items_2", isKey: true}, {index: 0}},
+		}, {
+			name: "brackets",
+			path: `.[1]["a key"].[2]`,
+			want: []jsonPathStep{{index: 1}, {key: "a key", isKey: true}, {index: 2}},
+		}, {
+			name:    "empty",
+			path:    "",
+			wantErr: true,
+		}, {
+			name:    "no leading dot",
+			path:    "items",
+			wantErr: true,
+		}, {
+			name:    "empty key",
+			path:    ".data..items",
+			wantErr: true,
+		}, {
+			name:    "trailing dot",
+			path:    ".data.",
+			wantErr: true,
+		}, {
+			name:    "invalid character",
+			path:    ".data items",
+			wantErr: true,
+		}, {
+			name:    "unterminated",
+			path:    ".data[0",
+			wantErr: true,
+		}, {
+			name:    "invalid index",
+			path:    ".data[-1]",
+			wantErr: true,
+		}, {
+			name:    "invalid key",
+			path:    `.data["a]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseJSONPath() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got, "parseJSONPath()")
+		})
+	}
+}
+
+func Test_baseTei_JSONPath(t *testing.T) {
+	standbyFunc := func() io.Reader {
+		return bytes.NewBuffer([]byte("standby data"))
+	}
+	tests := []struct {
+		name    string
+		builder Builder
+		input   io.Reader
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`{"items": [], "next": null}`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "missing",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`{"next": null}`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "data",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`{"next": null, "items": ["input data"]}`)),
+			want:    []byte(`{"next": null, "items": ["input data"]}`),
+		}, {
+			name:    "nested",
+			builder: NewBuilder().JSON(true).JSONPath(`.data["the items"][1]`),
+			input:   bytes.NewBuffer([]byte(`{"data": {"the items": [{"a": 1}, {}]}}`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "nested data",
+			builder: NewBuilder().JSON(true).JSONPath(`.data["the items"][1]`),
+			input:   bytes.NewBuffer([]byte(`{"data": {"the items": [{}, {"a": 1}]}}`)),
+			want:    []byte(`{"data": {"the items": [{}, {"a": 1}]}}`),
+		}, {
+			name:    "index out of range",
+			builder: NewBuilder().JSON(true).JSONPath(".[2]"),
+			input:   bytes.NewBuffer([]byte(`[1, 2]`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "scalar",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`"input data"`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "root",
+			builder: NewBuilder().JSON(true).JSONPath("."),
+			input:   bytes.NewBuffer([]byte(" {}\n")),
+			want:    []byte("standby data"),
+		}, {
+			name: "empty values",
+			builder: NewBuilder().JSON(true).JSONPath(".count").
+				JSONEmptyValues([]string{"0"}),
+			input: bytes.NewBuffer([]byte(`{"count": 0, "items": []}`)),
+			want:  []byte("standby data"),
+		}, {
+			name: "empty values(container)",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONEmptyValues([]string{`[null]`}),
+			input: bytes.NewBuffer([]byte(`{"items": [ null ]}`)),
+			want:  []byte("standby data"),
+		}, {
+			name: "empty values(container) data",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONEmptyValues([]string{`[null]`}),
+			input: bytes.NewBuffer([]byte(`{"items": [null, 1]}`)),
+			want:  []byte(`{"items": [null, 1]}`),
+		}, {
+			name:    "not JSON",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte("input data")),
+			want:    []byte("input data"),
+		}, {
+			name:    "invalid JSON",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`{"next": nul}`)),
+			want:    []byte(`{"next": nul}`),
+		}, {
+			name:    "no data",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte{}),
+			want:    []byte("standby data"),
+		}, {
+			name:    "json=false",
+			builder: NewBuilder().JSONPath(".items"),
+			input:   bytes.NewBuffer([]byte(`{"items": []}`)),
+			want:    []byte(`{"items": []}`),
+		}, {
+			name: "limit",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONSniffLimit(27),
+			input: bytes.NewBuffer([]byte(`{"next": "abc", "items": []}`)),
+			want:  []byte("standby data"),
+		}, {
+			name: "limit exceeded",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONSniffLimit(20),
+			input: bytes.NewBuffer([]byte(`{"next": "abc", "items": []}`)),
+			want:  []byte(`{"next": "abc", "items": []}`),
+		}, {
+			name: "limit min",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONSniffLimit(MinSniffLimit),
+			input: bytes.NewBuffer([]byte(`{"items":[1]}` + "\n")),
+			want:  []byte(`{"items":[1]}` + "\n"),
+		}, {
+			name: "limit min exceeded by a byte",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONSniffLimit(MinSniffLimit + 1),
+			input: bytes.NewBuffer([]byte(`{"items":[1]}` + "\n")),
+			want:  []byte(`{"items":[1]}` + "\n"),
+		}, {
+			name: "limit less than min",
+			builder: NewBuilder().JSON(true).JSONPath(".items").
+				JSONSniffLimit(MinSniffLimit - 1),
+			input:   bytes.NewBuffer([]byte(`{"items":[1]}` + "\n")),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name: "limit zero without path",
+			builder: NewBuilder().JSON(true).
+				JSONSniffLimit(0),
+			input:   bytes.NewBuffer([]byte(`[]`)),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name:    "invalid path",
+			builder: NewBuilder().JSON(true).JSONPath("items"),
+			input:   bytes.NewBuffer([]byte(`{"items": []}`)),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name:    "error",
+			builder: NewBuilder().JSON(true).JSONPath(".items"),
+			input: io.MultiReader(
+				bytes.NewBuffer([]byte(`{"items": [`)),
+				ErrReader(errors.New("test error")),
+			),
+			want:    []byte{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.builder.Standby(standbyFunc).Build().Switch(tt.input)
+			buf := bytes.NewBuffer([]byte{})
+			_, err := io.Copy(buf, r)
+			got := buf.Bytes()
+			assert.Equal(t, tt.want, got, "baseTei.Switch() read", string(got))
+			if assert.Equal(t, tt.wantErr, (err != nil), "baseTei.Reder() error in read") == false {
+				log.Println(err)
+			}
+		})
+	}
+}
+
+func Test_baseTei_JSONPath_streaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	switched := make(chan struct{})
+	go func() {
+		pw.Write([]byte(`{"items": [1, `))
+		// Switch が返るまでは残りを書き込まない(全体を読み込もうとするとデッドロックする).
+		<-switched
+		pw.Write([]byte(`2], "next": null}`))
+		pw.Close()
+	}()
+
+	r := NewBuilder().JSON(true).JSONPath(".items").
+		Standby(func() io.Reader {
+			return bytes.NewBuffer([]byte("standby data"))
+		}).
+		Build().Switch(pr)
+	close(switched)
+	buf := bytes.NewBuffer([]byte{})
+	_, err := io.Copy(buf, r)
+	assert.Nil(t, err, "baseTei.Switch() error in read")
+	assert.Equal(t, `{"items": [1, 2], "next": null}`, buf.String(), "baseTei.Switch() read")
+}
@@ -0,0 +1,146 @@
+package tei
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// LineDefaultFunc returns the default value of the empty line(lineNum starts at 1).
+type LineDefaultFunc func(lineNum int) (string, error)
+
+// LineBuilder builds Transformer that replaces the empty lines with the default value.
+type LineBuilder interface {
+	// Default sets the default value of the empty line.
+	Default(string) LineBuilder
+	// DefaultFunc sets the function that returns the default value of the empty line(overrides Default).
+	DefaultFunc(LineDefaultFunc) LineBuilder
+	// Separator sets the separator of the lines(default is '\n', e.g. 0 for NUL separated records).
+	Separator(byte) LineBuilder
+
+	// Build builds the instance of Transformer.
+	Build() Transformer
+}
+
+type lineBuilder struct {
+	defaultValue string
+	defaultFunc  LineDefaultFunc
+	separator    byte
+}
+
+func (b *lineBuilder) Default(defaultValue string) LineBuilder {
+	bb := b.branch()
+	bb.defaultValue = defaultValue
+	return bb
+}
+
+func (b *lineBuilder) DefaultFunc(defaultFunc LineDefaultFunc) LineBuilder {
+	bb := b.branch()
+	bb.defaultFunc = defaultFunc
+	return bb
+}
+
+func (b *lineBuilder) Separator(separator byte) LineBuilder {
+	bb := b.branch()
+	bb.separator = separator
+	return bb
+}
+
+func (b *lineBuilder) branch() *lineBuilder {
+	return b // 今回は再利用の予定はないので、そのまま返す。
+}
+
+func (b *lineBuilder) Build() Transformer {
+	defaultFunc := b.defaultFunc
+	if defaultFunc == nil {
+		defaultValue := b.defaultValue
+		defaultFunc = func(int) (string, error) {
+			return defaultValue, nil
+		}
+	}
+	return &lineTransformer{
+		defaultFunc: defaultFunc,
+		separator:   b.separator,
+	}
+}
+
+// NewLineBuilder returns the instance of LineBuilder.
+func NewLineBuilder() LineBuilder {
+	return &lineBuilder{
+		separator: '\n',
+	}
+}
+
+type lineTransformer struct {
+	defaultFunc LineDefaultFunc
+	separator   byte
+}
+
+// lineReader reads the lines that the empty lines are replaced.
+//
+// 1 行ずつ変換するので、メモリの使用量は行のサイズのみに依存する.
+type lineReader struct {
+	br          *bufio.Reader
+	defaultFunc LineDefaultFunc
+	separator   byte
+	out         bytes.Buffer
+	lineNum     int
+	err         error
+}
+
+// splitLine splits the line to the body and the separator.
+//
+// '\n' 区切りの場合は "\r\n" も区切りとして扱う.
+func (r *lineReader) splitLine(line []byte) (body []byte, sep []byte) {
+	body = line
+	if len(body) > 0 && body[len(body)-1] == r.separator {
+		body = body[:len(body)-1]
+		if r.separator == '\n' && len(body) > 0 && body[len(body)-1] == '\r' {
+			body = body[:len(body)-1]
+		}
+	}
+	return body, line[len(body):]
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && r.err == nil {
+		line, err := r.br.ReadBytes(r.separator)
+		if err != nil && err != io.EOF {
+			r.err = errors.Wrapf(err, "lineReader reading the input")
+			break
+		}
+		if len(line) == 0 && err == io.EOF {
+			r.err = io.EOF
+			break
+		}
+		r.lineNum++
+		body, sep := r.splitLine(line)
+		if len(bytes.TrimSpace(body)) == 0 {
+			v, derr := r.defaultFunc(r.lineNum)
+			if derr != nil {
+				r.err = errors.Wrapf(derr, "lineReader line %d", r.lineNum)
+				break
+			}
+			body = []byte(v)
+		}
+		r.out.Write(body)
+		r.out.Write(sep)
+		if err == io.EOF {
+			r.err = io.EOF
+		}
+	}
+	if r.out.Len() > 0 {
+		return r.out.Read(p)
+	}
+	return 0, r.err
+}
+
+func (t *lineTransformer) Transform(input io.Reader) io.Reader {
+	return &lineReader{
+		br:          bufio.NewReader(input),
+		defaultFunc: t.defaultFunc,
+		separator:   t.separator,
+	}
+}
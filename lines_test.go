@@ -0,0 +1,118 @@
+package tei
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lineTransformer_Transform(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder LineBuilder
+		input   io.Reader
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "basic",
+			builder: NewLineBuilder().Default("-"),
+			input:   strings.NewReader("a\n\n \t\nb\n"),
+			want:    "a\n-\n-\nb\n",
+		}, {
+			name:    "CRLF",
+			builder: NewLineBuilder().Default("-"),
+			input:   strings.NewReader("a\r\n\r\n \r\nb"),
+			want:    "a\r\n-\r\n-\r\nb",
+		}, {
+			name:    "last line",
+			builder: NewLineBuilder().Default("-"),
+			input:   strings.NewReader("a\n  "),
+			want:    "a\n-",
+		}, {
+			name:    "untouched",
+			builder: NewLineBuilder().Default("-"),
+			input:   strings.NewReader(" a \n\tb"),
+			want:    " a \n\tb",
+		}, {
+			name:    "no default",
+			builder: NewLineBuilder(),
+			input:   strings.NewReader("a\n \nb\n"),
+			want:    "a\n\nb\n",
+		}, {
+			name: "default func",
+			builder: NewLineBuilder().Default("-").DefaultFunc(func(lineNum int) (string, error) {
+				return fmt.Sprintf("line%d", lineNum), nil
+			}),
+			input: strings.NewReader("a\n\nb\n\n"),
+			want:  "a\nline2\nb\nline4\n",
+		}, {
+			name:    "NUL",
+			builder: NewLineBuilder().Default("-").Separator(0),
+			input:   strings.NewReader("a\x00\x00 \n\x00b\nc\x00"),
+			want:    "a\x00-\x00-\x00b\nc\x00",
+		}, {
+			name:    "no data",
+			builder: NewLineBuilder().Default("-"),
+			input:   strings.NewReader(""),
+			want:    "",
+		}, {
+			name: "default func error",
+			builder: NewLineBuilder().DefaultFunc(func(lineNum int) (string, error) {
+				return "", errors.New("test error")
+			}),
+			input:   strings.NewReader("a\n\nb\n"),
+			want:    "a\n",
+			wantErr: true,
+		}, {
+			name:    "error",
+			builder: NewLineBuilder().Default("-"),
+			input: io.MultiReader(
+				strings.NewReader("a\n"),
+				ErrReader(errors.New("test error")),
+			),
+			want:    "a\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.builder.Build().Transform(tt.input)
+			buf := bytes.NewBuffer([]byte{})
+			_, err := io.Copy(buf, r)
+			assert.Equal(t, tt.want, buf.String(), "lineTransformer.Transform() read")
+			if assert.Equal(t, tt.wantErr, (err != nil), "lineTransformer.Transform() error in read") == false {
+				log.Println(err)
+			}
+		})
+	}
+}
+
+func Test_lineTransformer_Transform_streaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	next := make(chan struct{})
+	go func() {
+		pw.Write([]byte("\n"))
+		// 1 行目を読み込むまでは残りを書き込まない(全体を読み込もうとするとデッドロックする).
+		<-next
+		pw.Write([]byte("a\n"))
+		pw.Close()
+	}()
+
+	r := NewLineBuilder().Default("-").Build().Transform(pr)
+	p := make([]byte, 100)
+	n, err := r.Read(p)
+	assert.Nil(t, err, "lineTransformer.Transform() error in read")
+	assert.Equal(t, "-\n", string(p[:n]), "lineTransformer.Transform() read")
+	close(next)
+	b, err := ioutil.ReadAll(r)
+	assert.Nil(t, err, "lineTransformer.Transform() error in read")
+	assert.Equal(t, "a\n", string(b), "lineTransformer.Transform() read")
+}
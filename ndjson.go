@@ -0,0 +1,236 @@
+package tei
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hankei6km/go-tei/internal/errors"
+)
+
+// NDJSONBuilder builds Transformer that applies the defaults per record of NDJSON.
+type NDJSONBuilder interface {
+	// DefaultRecord sets the record that replaces the blank line and the null record.
+	DefaultRecord(string) NDJSONBuilder
+	// Template sets the object that fills the missing or empty fields of the object record.
+	Template(string) NDJSONBuilder
+
+	// Build builds the instance of Transformer.
+	Build() Transformer
+}
+
+type ndjsonBuilder struct {
+	defaultRecord string
+	template      string
+}
+
+func (b *ndjsonBuilder) DefaultRecord(defaultRecord string) NDJSONBuilder {
+	bb := b.branch()
+	bb.defaultRecord = defaultRecord
+	return bb
+}
+
+func (b *ndjsonBuilder) Template(template string) NDJSONBuilder {
+	bb := b.branch()
+	bb.template = template
+	return bb
+}
+
+func (b *ndjsonBuilder) branch() *ndjsonBuilder {
+	return b // 今回は再利用の予定はないので、そのまま返す。
+}
+
+func (b *ndjsonBuilder) Build() Transformer {
+	return &ndjsonTransformer{
+		defaultRecord: b.defaultRecord,
+		template:      b.template,
+	}
+}
+
+// NewNDJSONBuilder returns the instance of NDJSONBuilder.
+func NewNDJSONBuilder() NDJSONBuilder {
+	return &ndjsonBuilder{}
+}
+
+type ndjsonTransformer struct {
+	defaultRecord string
+	template      string
+}
+
+// jsonField is the field of the object that keeps the order.
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeJSONObject decodes the object to the fields, returns false if b is not an object.
+func decodeJSONObject(b []byte) (fields []jsonField, ok bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, false, err
+	}
+	if tok != json.Delim('{') {
+		return nil, false, nil
+	}
+	fields = []jsonField{}
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, false, err
+		}
+		fields = append(fields, jsonField{key: keyTok.(string), value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, false, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false, fmt.Errorf("decodeJSONObject: trailing data after the object")
+	}
+	return fields, true, nil
+}
+
+// encodeJSONObject encodes the fields to the compact object.
+func encodeJSONObject(fields []jsonField) ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		if err := json.Compact(buf, f.value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fillJSONObject fills the missing or empty fields of the record from the template.
+//
+// 既存のフィールドの順序は保持し、存在しないフィールドは template の順序で末尾に追加する.
+// 変更がない場合は nil を返す.
+func fillJSONObject(record []byte, template []jsonField) ([]byte, error) {
+	fields, ok, err := decodeJSONObject(record)
+	if err != nil || ok == false {
+		return nil, err
+	}
+	filled := false
+	for _, t := range template {
+		found := false
+		for i, f := range fields {
+			if f.key == t.key {
+				found = true
+				if isEmptyJSON(f.value, DefaultJSONEmptyValues) {
+					fields[i].value = t.value
+					filled = true
+				}
+			}
+		}
+		if found == false {
+			fields = append(fields, t)
+			filled = true
+		}
+	}
+	if filled == false {
+		return nil, nil
+	}
+	return encodeJSONObject(fields)
+}
+
+// ndjsonReader reads the transformed records.
+//
+// 1 レコードずつ変換するので、メモリの使用量はレコードのサイズのみに依存する.
+type ndjsonReader struct {
+	br            *bufio.Reader
+	defaultRecord []byte
+	template      []jsonField
+	out           bytes.Buffer
+	lineNum       int
+	err           error
+}
+
+// transform transforms the line(the newline is not included).
+func (r *ndjsonReader) transform(line []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(line)
+	if r.defaultRecord != nil && (len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))) {
+		line = r.defaultRecord
+	} else if len(trimmed) == 0 {
+		return line, nil
+	}
+	if json.Valid(line) == false {
+		return nil, fmt.Errorf("invalid JSON record")
+	}
+	if r.template == nil {
+		return line, nil
+	}
+	filled, err := fillJSONObject(line, r.template)
+	if err != nil || filled == nil {
+		return line, err
+	}
+	return filled, nil
+}
+
+func (r *ndjsonReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && r.err == nil {
+		line, err := r.br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			r.err = errors.Wrapf(err, "ndjsonReader reading the input")
+			break
+		}
+		if len(line) == 0 && err == io.EOF {
+			r.err = io.EOF
+			break
+		}
+		r.lineNum++
+		body := bytes.TrimRight(line, "\r\n")
+		newline := line[len(body):]
+		transformed, terr := r.transform(body)
+		if terr != nil {
+			r.err = errors.Wrapf(terr, "ndjsonReader line %d", r.lineNum)
+			break
+		}
+		r.out.Write(transformed)
+		r.out.Write(newline)
+		if err == io.EOF {
+			r.err = io.EOF
+		}
+	}
+	if r.out.Len() > 0 {
+		return r.out.Read(p)
+	}
+	return 0, r.err
+}
+
+func (t *ndjsonTransformer) Transform(input io.Reader) io.Reader {
+	r := &ndjsonReader{
+		br: bufio.NewReader(input),
+	}
+	if t.defaultRecord != "" {
+		if json.Valid([]byte(t.defaultRecord)) == false {
+			return ErrReader(fmt.Errorf("ndjsonTransformer.Transform invalid default record: %s", t.defaultRecord))
+		}
+		var buf bytes.Buffer
+		json.Compact(&buf, []byte(t.defaultRecord))
+		r.defaultRecord = buf.Bytes()
+	}
+	if t.template != "" {
+		template, ok, err := decodeJSONObject([]byte(t.template))
+		if err != nil || ok == false {
+			return ErrReader(fmt.Errorf("ndjsonTransformer.Transform the template must be an object: %s", t.template))
+		}
+		r.template = template
+	}
+	return r
+}
@@ -0,0 +1,133 @@
+package tei
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ndjsonTransformer_Transform(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder NDJSONBuilder
+		input   io.Reader
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "basic",
+			builder: NewNDJSONBuilder(),
+			input:   strings.NewReader("{\"a\": 1}\n\nnull\n[1, 2]\n"),
+			want:    "{\"a\": 1}\n\nnull\n[1, 2]\n",
+		}, {
+			name:    "default record",
+			builder: NewNDJSONBuilder().DefaultRecord(`{"a": 0}`),
+			input:   strings.NewReader("{\"a\": 1}\n\n  null \r\n \t\n[1, 2]"),
+			want:    "{\"a\": 1}\n{\"a\":0}\n{\"a\":0}\r\n{\"a\":0}\n[1, 2]",
+		}, {
+			name:    "default record last line",
+			builder: NewNDJSONBuilder().DefaultRecord(`{"a": 0}`),
+			input:   strings.NewReader("{\"a\": 1}\nnull"),
+			want:    "{\"a\": 1}\n{\"a\":0}",
+		}, {
+			name:    "template",
+			builder: NewNDJSONBuilder().Template(`{"name": "unknown", "tags": ["none"], "count": 0}`),
+			input: strings.NewReader(strings.Join([]string{
+				`{"name": "test", "tags": ["a"], "count": 1}`,
+				`{"count": 2, "name": "", "tags": []}`,
+				`{"id": 3, "name": null}`,
+				`{}`,
+				`[{}]`,
+				``,
+				``,
+			}, "\n")),
+			want: strings.Join([]string{
+				`{"name": "test", "tags": ["a"], "count": 1}`,
+				`{"count":2,"name":"unknown","tags":["none"]}`,
+				`{"id":3,"name":"unknown","tags":["none"],"count":0}`,
+				`{"name":"unknown","tags":["none"],"count":0}`,
+				`[{}]`,
+				``,
+				``,
+			}, "\n"),
+		}, {
+			name: "default record and template",
+			builder: NewNDJSONBuilder().
+				DefaultRecord(`{"id": null}`).
+				Template(`{"id": 0, "name": "unknown"}`),
+			input: strings.NewReader("{\"id\": 1, \"name\": \"test\"}\nnull\n\n"),
+			want:  "{\"id\": 1, \"name\": \"test\"}\n{\"id\":0,\"name\":\"unknown\"}\n{\"id\":0,\"name\":\"unknown\"}\n",
+		}, {
+			name:    "no data",
+			builder: NewNDJSONBuilder().DefaultRecord(`{}`),
+			input:   strings.NewReader(""),
+			want:    "",
+		}, {
+			name:    "invalid record",
+			builder: NewNDJSONBuilder().DefaultRecord(`{}`),
+			input:   strings.NewReader("{\"a\": 1}\n{\"a\": \n"),
+			want:    "{\"a\": 1}\n",
+			wantErr: true,
+		}, {
+			name:    "invalid default record",
+			builder: NewNDJSONBuilder().DefaultRecord(`{`),
+			input:   strings.NewReader("{\"a\": 1}\n"),
+			want:    "",
+			wantErr: true,
+		}, {
+			name:    "invalid template",
+			builder: NewNDJSONBuilder().Template(`[]`),
+			input:   strings.NewReader("{\"a\": 1}\n"),
+			want:    "",
+			wantErr: true,
+		}, {
+			name:    "error",
+			builder: NewNDJSONBuilder().DefaultRecord(`{}`),
+			input: io.MultiReader(
+				strings.NewReader("{\"a\": 1}\n"),
+				ErrReader(errors.New("test error")),
+			),
+			want:    "{\"a\": 1}\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.builder.Build().Transform(tt.input)
+			buf := bytes.NewBuffer([]byte{})
+			_, err := io.Copy(buf, r)
+			assert.Equal(t, tt.want, buf.String(), "ndjsonTransformer.Transform() read")
+			if assert.Equal(t, tt.wantErr, (err != nil), "ndjsonTransformer.Transform() error in read") == false {
+				log.Println(err)
+			}
+		})
+	}
+}
+
+func Test_ndjsonTransformer_Transform_streaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	next := make(chan struct{})
+	go func() {
+		pw.Write([]byte("null\n"))
+		// 1 件目を読み込むまでは残りを書き込まない(全体を読み込もうとするとデッドロックする).
+		<-next
+		pw.Write([]byte("{\"a\": 1}\n"))
+		pw.Close()
+	}()
+
+	r := NewNDJSONBuilder().DefaultRecord(`{"a": 0}`).Build().Transform(pr)
+	p := make([]byte, 100)
+	n, err := r.Read(p)
+	assert.Nil(t, err, "ndjsonTransformer.Transform() error in read")
+	assert.Equal(t, "{\"a\":0}\n", string(p[:n]), "ndjsonTransformer.Transform() read")
+	close(next)
+	b, err := ioutil.ReadAll(r)
+	assert.Nil(t, err, "ndjsonTransformer.Transform() error in read")
+	assert.Equal(t, "{\"a\": 1}\n", string(b), "ndjsonTransformer.Transform() read")
+}
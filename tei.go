@@ -3,6 +3,8 @@ package tei
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 
@@ -12,6 +14,38 @@ import (
 // StandbyFunc returns io.Reader built by the standby source.
 type StandbyFunc func() io.Reader
 
+// Reason is the reason why Tei switched the input to the standby source.
+type Reason string
+
+const (
+	// ReasonNoData is that no data from the input.
+	ReasonNoData Reason = "no-data"
+	// ReasonNewline is that the input has only a newline.
+	ReasonNewline Reason = "newline"
+	// ReasonTerminal is that the input is opened on a terminal.
+	ReasonTerminal Reason = "terminal"
+	// ReasonEmptyJSON is that the input has only an empty JSON value(e.g. null, [], {}).
+	ReasonEmptyJSON Reason = "empty-json"
+	// ReasonHeaderOnly is that the input has only a header of CSV.
+	ReasonHeaderOnly Reason = "header-only"
+)
+
+// DefaultJSONEmptyValues is the JSON values that are treated as no data by default.
+var DefaultJSONEmptyValues = []string{`null`, `[]`, `{}`, `""`}
+
+// MinSniffLimit is the size of the input that is always sniffed, the sniff limits must not be less than it.
+const MinSniffLimit int64 = 3
+
+// DefaultJSONSniffLimit is the max size of the input that is sniffed as JSON by default.
+const DefaultJSONSniffLimit int64 = 4096
+
+// SwitchFunc is called with the reason and the sniffed bytes just before the standby source is activated.
+//
+// The sniffed bytes are the input that was read to decide the reason.
+// They are the whole input except with JSONPath, that stops sniffing once the selected node is decided,
+// so the sniffed bytes may be only the head of the input and the rest is left unread in the input.
+type SwitchFunc func(reason Reason, sniffed []byte)
+
 // ErrReader returns io.Reader that retuns the err instead of io.EOF.
 func ErrReader(err error) io.Reader {
 	// TODO: err 伝播用の reader を作る?
@@ -22,12 +56,63 @@ func ErrReader(err error) io.Reader {
 	return r
 }
 
+// errSniffLimit is returned by limitedTeeReader if the size of read bytes reaches the limit.
+var errSniffLimit = fmt.Errorf("the size of the input reaches the limit")
+
+// limitedTeeReader writes to buf what it reads from r up to the limit.
+//
+// r から返されたエラー(io.EOF 以外)は err に保存する(decoder 等のエラーと区別するため).
+type limitedTeeReader struct {
+	r        io.Reader
+	buf      *bytes.Buffer
+	limit    int64
+	err      error
+	exceeded bool
+}
+
+func (l *limitedTeeReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, errSniffLimit
+	}
+	remain := l.limit - int64(l.buf.Len())
+	if remain <= 0 {
+		// limit ちょうどで終わる入力を判定するため 1 byte だけ読み込む(読み込んだ内容は buf に残す).
+		var b [1]byte
+		n, err := io.ReadFull(l.r, b[:])
+		l.buf.Write(b[:n])
+		switch {
+		case err == io.EOF:
+			return 0, io.EOF
+		case err != nil:
+			l.err = err
+			return 0, err
+		}
+		l.exceeded = true
+		return 0, errSniffLimit
+	}
+	if int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := l.r.Read(p)
+	l.buf.Write(p[:n])
+	if err != nil && err != io.EOF {
+		l.err = err
+	}
+	return n, err
+}
+
 // Tei is an io.Reader switcher.
 type Tei interface {
 	// Switch activates the standby source instead of the input, if nothing data from the input.
 	Switch(input io.Reader) (r io.Reader)
 }
 
+// Transformer is an io.Reader transformer that applies the defaults to the input while streaming.
+type Transformer interface {
+	// Transform returns io.Reader that reads the transformed input.
+	Transform(input io.Reader) (r io.Reader)
+}
+
 // Builder builds Tei.
 type Builder interface {
 	// Standy sets the function that return the standby source.
@@ -36,6 +121,30 @@ type Builder interface {
 	IgnoreLeadingNewline(bool) Builder
 	// SwitchByTerminal sets the flag that force switch if the input is opened on a terminal.
 	SwitchByTerminal(bool) Builder
+	// OnSwitch sets the function that is called before the standby source is activated.
+	OnSwitch(SwitchFunc) Builder
+	// JSON sets the flag that treat the empty JSON value as no data.
+	JSON(bool) Builder
+	// JSONEmptyValues sets the JSON values that are treated as no data(default is DefaultJSONEmptyValues).
+	JSONEmptyValues([]string) Builder
+	// JSONSniffLimit sets the max size of the input that is sniffed as JSON(default is DefaultJSONSniffLimit).
+	//
+	// Switch returns the error reader if the limit is less than MinSniffLimit.
+	JSONSniffLimit(int64) Builder
+	// JSONPath sets the jq-lite path(e.g. ".items") of the node that is checked instead of the whole value.
+	//
+	// The input is switched if the node is missing or empty.
+	JSONPath(string) Builder
+	// CSV sets the flag that treat the CSV that has only a header as no data.
+	CSV(bool) Builder
+	// CSVComma sets the field delimiter of CSV(default is ',').
+	CSVComma(rune) Builder
+	// CSVSniffLimit sets the max size of the input that is sniffed as CSV(default is DefaultCSVSniffLimit).
+	//
+	// Switch returns the error reader if the limit is less than MinSniffLimit.
+	CSVSniffLimit(int64) Builder
+	// CSVFallback sets how the standby source is activated if the input has only a header.
+	CSVFallback(CSVFallback) Builder
 
 	// Branch() Builder
 
@@ -47,6 +156,15 @@ type baseBuilder struct {
 	standby              StandbyFunc
 	ignoreLeadingNewline bool
 	switchByTerminal     bool
+	onSwitch             SwitchFunc
+	json                 bool
+	jsonEmptyValues      []string
+	jsonSniffLimit       int64
+	jsonPath             string
+	csv                  bool
+	csvComma             rune
+	csvSniffLimit        int64
+	csvFallback          CSVFallback
 }
 
 func (b *baseBuilder) Standby(standby StandbyFunc) Builder {
@@ -67,6 +185,60 @@ func (b *baseBuilder) SwitchByTerminal(switchByTerminal bool) Builder {
 	return bb
 }
 
+func (b *baseBuilder) OnSwitch(onSwitch SwitchFunc) Builder {
+	bb := b.branch()
+	bb.onSwitch = onSwitch
+	return bb
+}
+
+func (b *baseBuilder) JSON(json bool) Builder {
+	bb := b.branch()
+	bb.json = json
+	return bb
+}
+
+func (b *baseBuilder) JSONEmptyValues(jsonEmptyValues []string) Builder {
+	bb := b.branch()
+	bb.jsonEmptyValues = jsonEmptyValues
+	return bb
+}
+
+func (b *baseBuilder) JSONSniffLimit(jsonSniffLimit int64) Builder {
+	bb := b.branch()
+	bb.jsonSniffLimit = jsonSniffLimit
+	return bb
+}
+
+func (b *baseBuilder) JSONPath(jsonPath string) Builder {
+	bb := b.branch()
+	bb.jsonPath = jsonPath
+	return bb
+}
+
+func (b *baseBuilder) CSV(csv bool) Builder {
+	bb := b.branch()
+	bb.csv = csv
+	return bb
+}
+
+func (b *baseBuilder) CSVComma(csvComma rune) Builder {
+	bb := b.branch()
+	bb.csvComma = csvComma
+	return bb
+}
+
+func (b *baseBuilder) CSVSniffLimit(csvSniffLimit int64) Builder {
+	bb := b.branch()
+	bb.csvSniffLimit = csvSniffLimit
+	return bb
+}
+
+func (b *baseBuilder) CSVFallback(csvFallback CSVFallback) Builder {
+	bb := b.branch()
+	bb.csvFallback = csvFallback
+	return bb
+}
+
 func (b *baseBuilder) branch() *baseBuilder {
 	// return &(*b)
 	return b // 今回は再利用の予定はないので、そのまま返す。
@@ -84,9 +256,35 @@ type baseTei struct {
 	standby              StandbyFunc
 	ignoreLeadingNewline bool
 	switchByTerminal     bool
+	onSwitch             SwitchFunc
+	json                 bool
+	jsonEmptyValues      []string
+	jsonSniffLimit       int64
+	jsonPath             string
+	csv                  bool
+	csvComma             rune
+	csvSniffLimit        int64
+	csvFallback          CSVFallback
+}
+
+func (t *baseTei) switchTo(reason Reason, sniffed []byte) io.Reader {
+	if t.onSwitch != nil {
+		t.onSwitch(reason, sniffed)
+	}
+	return t.standby()
 }
 
 func (t *baseTei) Switch(input io.Reader) (r io.Reader) {
+	if t.json && t.jsonSniffLimit < MinSniffLimit {
+		return ErrReader(fmt.Errorf("baseTei.Switch the JSON sniff limit must be %d or more: %d", MinSniffLimit, t.jsonSniffLimit))
+	}
+	if t.csv && t.csvSniffLimit < MinSniffLimit {
+		return ErrReader(fmt.Errorf("baseTei.Switch the CSV sniff limit must be %d or more: %d", MinSniffLimit, t.csvSniffLimit))
+	}
+	if t.csv && validCSVFallback(t.csvFallback) == false {
+		// 入力を読み込む前に(切り替えが発生しなくても)検出する.
+		return ErrReader(fmt.Errorf("baseTei.Switch invalid CSV fallback: %s", t.csvFallback))
+	}
 	if t.switchByTerminal {
 		if file, ok := input.(*os.File); ok {
 			stat, err := file.Stat()
@@ -94,32 +292,89 @@ func (t *baseTei) Switch(input io.Reader) (r io.Reader) {
 				return ErrReader(errors.Wrapf(err, "baseTei.Switch checking switchByTerminal"))
 			}
 			if stat.Mode()&os.ModeDevice != 0 {
-				return t.standby()
+				return t.switchTo(ReasonTerminal, []byte{})
 			}
 		}
 	}
 
 	buf := bytes.NewBuffer([]byte{})
-	n, err := io.CopyN(buf, input, 3)
+	n, err := io.CopyN(buf, input, MinSniffLimit)
 	switch {
 	case err == io.EOF:
 		switch {
 		case n == 0:
-			return t.standby()
+			return t.switchTo(ReasonNoData, buf.Bytes())
 		case t.ignoreLeadingNewline:
 			b := buf.Bytes()
 			switch {
 			case n == 2 && b[0] == '\r' && b[1] == '\n':
-				return t.standby()
+				return t.switchTo(ReasonNewline, b)
 			case n == 1 && b[0] == '\n':
-				return t.standby()
+				return t.switchTo(ReasonNewline, b)
 			case n == 1 && b[0] == '\r':
-				return t.standby()
+				return t.switchTo(ReasonNewline, b)
 			}
 		}
 	case err != nil && err != io.EOF:
 		return ErrReader(errors.Wrapf(err, "baseTei.Switch sniffing the inpu"))
 	}
+	switch {
+	case t.json && t.jsonPath != "":
+		return t.sniffJSONPath(buf, input)
+	case t.json:
+		return t.sniffJSON(buf, input)
+	case t.csv:
+		return t.sniffCSV(buf, input)
+	}
+	return io.MultiReader(buf, input)
+}
+
+// canonicalJSON returns the JSON value that is re-encoded(e.g. the keys are sorted, no whitespaces).
+//
+// 値の後に空白以外が続く場合(複数の値等)はエラーとする.
+func canonicalJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("canonicalJSON: trailing data after the value")
+	}
+	return json.Marshal(v)
+}
+
+// isEmptyJSON returns true if b is the one of the empty JSON values.
+//
+// 不正な JSON の値(emptyValues 側も含む)は一致しない.
+func isEmptyJSON(b []byte, emptyValues []string) bool {
+	v, err := canonicalJSON(b)
+	if err != nil {
+		return false
+	}
+	for _, e := range emptyValues {
+		if ev, err := canonicalJSON([]byte(e)); err == nil && bytes.Equal(v, ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffJSON sniffs the input up to the limit, and switches if the input is an empty JSON value.
+//
+// limit を超える入力は(値に関係なく)データありとして扱う.
+// データありの場合は sniff した内容をそのまま再現する.
+func (t *baseTei) sniffJSON(buf *bytes.Buffer, input io.Reader) io.Reader {
+	// limit を超えたことを判定するため 1 byte 余分に読み込む.
+	_, err := io.CopyN(buf, input, t.jsonSniffLimit-int64(buf.Len())+1)
+	switch {
+	case err == io.EOF:
+		if isEmptyJSON(buf.Bytes(), t.jsonEmptyValues) {
+			return t.switchTo(ReasonEmptyJSON, buf.Bytes())
+		}
+	case err != nil:
+		return ErrReader(errors.Wrapf(err, "baseTei.Switch sniffing the input as JSON"))
+	}
 	return io.MultiReader(buf, input)
 }
 
@@ -128,6 +383,15 @@ func newBaseTei(b *baseBuilder) *baseTei {
 		standby:              b.standby,
 		ignoreLeadingNewline: b.ignoreLeadingNewline,
 		switchByTerminal:     b.switchByTerminal,
+		onSwitch:             b.onSwitch,
+		json:                 b.json,
+		jsonEmptyValues:      b.jsonEmptyValues,
+		jsonSniffLimit:       b.jsonSniffLimit,
+		jsonPath:             b.jsonPath,
+		csv:                  b.csv,
+		csvComma:             b.csvComma,
+		csvSniffLimit:        b.csvSniffLimit,
+		csvFallback:          b.csvFallback,
 	}
 }
 
@@ -136,5 +400,9 @@ func NewBuilder() Builder {
 	return &baseBuilder{
 		ignoreLeadingNewline: true,
 		switchByTerminal:     true,
+		jsonEmptyValues:      DefaultJSONEmptyValues,
+		jsonSniffLimit:       DefaultJSONSniffLimit,
+		csvComma:             ',',
+		csvSniffLimit:        DefaultCSVSniffLimit,
 	}
 }
@@ -166,3 +166,208 @@ func Test_baseTei_Reader(t *testing.T) {
 		})
 	}
 }
+
+func Test_baseTei_OnSwitch(t *testing.T) {
+	standbyFunc := func() io.Reader {
+		return bytes.NewBuffer([]byte("standby data"))
+	}
+	tests := []struct {
+		name        string
+		builder     Builder
+		input       io.Reader
+		wantCalled  bool
+		wantReason  Reason
+		wantSniffed []byte
+	}{
+		{
+			name:    "data",
+			builder: NewBuilder(),
+			input:   bytes.NewBuffer([]byte("input data")),
+		}, {
+			name:        "no data",
+			builder:     NewBuilder(),
+			input:       bytes.NewBuffer([]byte{}),
+			wantCalled:  true,
+			wantReason:  ReasonNoData,
+			wantSniffed: []byte{},
+		}, {
+			name:        "CRLF",
+			builder:     NewBuilder(),
+			input:       bytes.NewBuffer([]byte("\r\n")),
+			wantCalled:  true,
+			wantReason:  ReasonNewline,
+			wantSniffed: []byte("\r\n"),
+		}, {
+			name:        "terminal",
+			builder:     NewBuilder(),
+			input:       os.Stdin,
+			wantCalled:  true,
+			wantReason:  ReasonTerminal,
+			wantSniffed: []byte{},
+		}, {
+			name:        "empty JSON",
+			builder:     NewBuilder().JSON(true),
+			input:       bytes.NewBuffer([]byte(" [ ]\n")),
+			wantCalled:  true,
+			wantReason:  ReasonEmptyJSON,
+			wantSniffed: []byte(" [ ]\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			var gotReason Reason
+			var gotSniffed []byte
+			r := tt.builder.
+				Standby(standbyFunc).
+				OnSwitch(func(reason Reason, sniffed []byte) {
+					called = true
+					gotReason = reason
+					gotSniffed = sniffed
+				}).
+				Build().Switch(tt.input)
+			io.Copy(ioutil.Discard, r)
+			assert.Equal(t, tt.wantCalled, called, "baseTei.Switch() called onSwitch")
+			assert.Equal(t, tt.wantReason, gotReason, "baseTei.Switch() reason")
+			assert.Equal(t, tt.wantSniffed, gotSniffed, "baseTei.Switch() sniffed")
+		})
+	}
+}
+
+func Test_baseTei_JSON(t *testing.T) {
+	standbyFunc := func() io.Reader {
+		return bytes.NewBuffer([]byte("standby data"))
+	}
+	tests := []struct {
+		name    string
+		builder Builder
+		input   io.Reader
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:    "null",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("null")),
+			want:    []byte("standby data"),
+		}, {
+			name:    "array",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("[]")),
+			want:    []byte("standby data"),
+		}, {
+			name:    "object",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("{}\n")),
+			want:    []byte("standby data"),
+		}, {
+			name:    "string",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte(`""`)),
+			want:    []byte("standby data"),
+		}, {
+			name:    "whitespaces",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte(" \r\n\t{\n  }\r\n\n")),
+			want:    []byte("standby data"),
+		}, {
+			name:    "data",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte(` [ "input data" ]` + "\n")),
+			want:    []byte(` [ "input data" ]` + "\n"),
+		}, {
+			name:    "false",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("false")),
+			want:    []byte("false"),
+		}, {
+			name:    "multiple values",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("[] []")),
+			want:    []byte("[] []"),
+		}, {
+			name:    "invalid",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("[}")),
+			want:    []byte("[}"),
+		}, {
+			name:    "not JSON",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("input data")),
+			want:    []byte("input data"),
+		}, {
+			name:    "no data",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte{}),
+			want:    []byte("standby data"),
+		}, {
+			name:    "newline",
+			builder: NewBuilder().JSON(true),
+			input:   bytes.NewBuffer([]byte("\n")),
+			want:    []byte("standby data"),
+		}, {
+			name:    "json=false",
+			builder: NewBuilder(),
+			input:   bytes.NewBuffer([]byte("[]")),
+			want:    []byte("[]"),
+		}, {
+			name: "empty values",
+			builder: NewBuilder().JSON(true).
+				JSONEmptyValues([]string{`false`, `0`, `{"items": [], "count": 0}`}),
+			input: bytes.NewBuffer([]byte(`{"count":0,"items":[ ]}`)),
+			want:  []byte("standby data"),
+		}, {
+			name: "empty values not matched",
+			builder: NewBuilder().JSON(true).
+				JSONEmptyValues([]string{`false`, `0`}),
+			input: bytes.NewBuffer([]byte("[]")),
+			want:  []byte("[]"),
+		}, {
+			name: "invalid empty values",
+			builder: NewBuilder().JSON(true).
+				JSONEmptyValues([]string{`[`, `[]`}),
+			input: bytes.NewBuffer([]byte("[]")),
+			want:  []byte("standby data"),
+		}, {
+			name: "limit",
+			builder: NewBuilder().JSON(true).
+				JSONSniffLimit(8),
+			input: bytes.NewBuffer([]byte("[      ]")),
+			want:  []byte("standby data"),
+		}, {
+			name: "limit exceeded",
+			builder: NewBuilder().JSON(true).
+				JSONSniffLimit(8),
+			input: bytes.NewBuffer([]byte("[       ]")),
+			want:  []byte("[       ]"),
+		}, {
+			name: "limit less than sniffed",
+			builder: NewBuilder().JSON(true).
+				JSONSniffLimit(1),
+			input:   bytes.NewBuffer([]byte("[]")),
+			want:    []byte{},
+			wantErr: true,
+		}, {
+			name:    "error",
+			builder: NewBuilder().JSON(true),
+			input: io.MultiReader(
+				bytes.NewBuffer([]byte("[  ")),
+				ErrReader(errors.New("test error")),
+			),
+			want:    []byte{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.builder.Standby(standbyFunc).Build().Switch(tt.input)
+			buf := bytes.NewBuffer([]byte{})
+			_, err := io.Copy(buf, r)
+			got := buf.Bytes()
+			assert.Equal(t, tt.want, got, "baseTei.Switch() read", string(got))
+			if assert.Equal(t, tt.wantErr, (err != nil), "baseTei.Reder() error in read") == false {
+				log.Println(err)
+			}
+		})
+	}
+}